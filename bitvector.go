@@ -1,6 +1,13 @@
 package main
 
-import "math/bits"
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
 
 type Bitvec struct {
 	Bytes []uint64
@@ -17,7 +24,50 @@ func NewBitvec(size int) *Bitvec {
 	}
 }
 
+// Grow extends the vector to hold newSize bits, keeping the bits already set.
+// It never shrinks.
+func (bv *Bitvec) Grow(newSize int) {
+	if newSize <= bv.Size {
+		return
+	}
+
+	numBytes := (newSize + 63) / 64
+	if numBytes > len(bv.Bytes) {
+		bv.Bytes = append(bv.Bytes, make([]uint64, numBytes-len(bv.Bytes))...)
+	}
+	bv.Size = newSize
+}
+
+// BitvecFromBoolSlice returns a bitvec of len(bits) with index i set wherever
+// bits[i] is true
+func BitvecFromBoolSlice(bits []bool) *Bitvec {
+	bv := NewBitvec(len(bits))
+	for i, ok := range bits {
+		if ok {
+			bv.Set(i)
+		}
+	}
+
+	return bv
+}
+
+// ToBoolSlice returns Size bools, true wherever a bit is set
+func (bv *Bitvec) ToBoolSlice() []bool {
+	bits := make([]bool, bv.Size)
+	for i := range bv.Size {
+		bits[i] = bv.Get(i)
+	}
+
+	return bits
+}
+
+// Set sets the bit at index. It panics if index is outside [0, Size); use
+// Grow first to make room.
 func (bv *Bitvec) Set(index int) {
+	if index < 0 || index >= bv.Size {
+		panic(fmt.Sprintf("Bitvec.Set: index %d out of range for size %d", index, bv.Size))
+	}
+
 	byteIndex := index / 64
 	bitIndex := index % 64
 	if (bv.Bytes[byteIndex] & (1 << bitIndex)) == 0 {
@@ -26,6 +76,16 @@ func (bv *Bitvec) Set(index int) {
 	}
 }
 
+// SetAll sets every bit below Size
+func (bv *Bitvec) SetAll() {
+	for i := range bv.Bytes {
+		bv.Bytes[i] = ^uint64(0)
+		// mask off anything past Size in the last word
+		bv.Bytes[i] = bv.maskedWord(i)
+	}
+	bv.Count = bv.Size
+}
+
 func (bv *Bitvec) Get(index int) bool {
 	byteIndex := index / 64
 	bitIndex := index % 64
@@ -33,12 +93,238 @@ func (bv *Bitvec) Get(index int) bool {
 }
 
 func (bv *Bitvec) And(other *Bitvec) *Bitvec {
+	if Profile {
+		defer recordProfile("Bitvec.And", time.Now())
+	}
+
 	minLen := min(len(other.Bytes), len(bv.Bytes))
 
-	result := &Bitvec{Bytes: make([]uint64, minLen), Count: 0}
+	result := &Bitvec{Bytes: make([]uint64, minLen), Size: min(bv.Size, other.Size), Count: 0}
 	for i := range minLen {
 		result.Bytes[i] = bv.Bytes[i] & other.Bytes[i]
 		result.Count += bits.OnesCount64(result.Bytes[i])
 	}
 	return result
 }
+
+// AndInto writes bv & other into dst, reusing dst's Bytes when they're big
+// enough. dst may be bv or other.
+func (bv *Bitvec) AndInto(dst, other *Bitvec) {
+	if Profile {
+		defer recordProfile("Bitvec.AndInto", time.Now())
+	}
+
+	minLen := min(len(other.Bytes), len(bv.Bytes))
+
+	if cap(dst.Bytes) < minLen {
+		dst.Bytes = make([]uint64, minLen)
+	}
+	dst.Bytes = dst.Bytes[:minLen]
+	dst.Size = min(bv.Size, other.Size)
+	dst.Count = 0
+
+	for i := range minLen {
+		dst.Bytes[i] = bv.Bytes[i] & other.Bytes[i]
+		dst.Count += bits.OnesCount64(dst.Bytes[i])
+	}
+}
+
+// Intersects reports whether bv and other have any bit set in common. Unlike
+// And, it doesn't allocate.
+func (bv *Bitvec) Intersects(other *Bitvec) bool {
+	for i := range min(len(bv.Bytes), len(other.Bytes)) {
+		if bv.Bytes[i]&other.Bytes[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scratchBitvecs holds bitvecs for hot loops to use with AndInto instead of
+// allocating a new one on every And
+var scratchBitvecs = sync.Pool{
+	New: func() any { return &Bitvec{} },
+}
+
+// getScratchBitvec returns a bitvec with arbitrary contents to pass to AndInto.
+// Give it back with putScratchBitvec once nothing references it.
+func getScratchBitvec() *Bitvec {
+	return scratchBitvecs.Get().(*Bitvec)
+}
+
+func putScratchBitvec(bv *Bitvec) {
+	scratchBitvecs.Put(bv)
+}
+
+// VerifyCount reports whether Count matches the number of bits actually set.
+// Bits past Size don't count, like in Hash and IsSubsetOf.
+func (bv *Bitvec) VerifyCount() bool {
+	count := 0
+	for i := range bv.Bytes {
+		count += bits.OnesCount64(bv.maskedWord(i))
+	}
+
+	return count == bv.Count
+}
+
+// maskedWord returns word i of Bytes with anything past Size masked off
+func (bv *Bitvec) maskedWord(i int) uint64 {
+	word := bv.Bytes[i]
+	if remaining := bv.Size - i*64; remaining < 64 {
+		word &= uint64(1)<<max(remaining, 0) - 1
+	}
+
+	return word
+}
+
+// IsSubsetOf reports whether every bit set in bv is also set in other. Bits
+// past bv.Size are ignored.
+func (bv *Bitvec) IsSubsetOf(other *Bitvec) bool {
+	for i := range bv.Bytes {
+		word := bv.maskedWord(i)
+
+		var otherWord uint64
+		if i < len(other.Bytes) {
+			otherWord = other.Bytes[i]
+		}
+		if word&^otherWord != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fnvOffset64 and fnvPrime64 are the 64 bit FNV-1a parameters
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// Hash returns an FNV-1a hash of the set bits, for using candidate sets as map
+// keys. Bits past Size and trailing zero words are left out, so vectors with
+// the same bits set hash the same whatever their Size or capacity.
+func (bv *Bitvec) Hash() uint64 {
+	n := len(bv.Bytes)
+	for n > 0 && bv.maskedWord(n-1) == 0 {
+		n--
+	}
+
+	// FNV-1a over each word's little endian bytes, done by hand so that
+	// hashing doesn't allocate
+	h := uint64(fnvOffset64)
+	for i := range n {
+		word := bv.maskedWord(i)
+		for range 8 {
+			h ^= word & 0xff
+			h *= fnvPrime64
+			word >>= 8
+		}
+	}
+
+	return h
+}
+
+// CountInRange returns the number of set bits with index in [lo, hi)
+func (bv *Bitvec) CountInRange(lo, hi int) int {
+	lo = max(lo, 0)
+	hi = min(hi, len(bv.Bytes)*64)
+	if lo >= hi {
+		return 0
+	}
+
+	loByte, loBit := lo/64, lo%64
+	hiByte, hiBit := hi/64, hi%64
+
+	// mask off the bits below lo in the first word
+	loMask := ^uint64(0) << loBit
+
+	if loByte == hiByte {
+		hiMask := uint64(1)<<hiBit - 1
+		return bits.OnesCount64(bv.Bytes[loByte] & loMask & hiMask)
+	}
+
+	count := bits.OnesCount64(bv.Bytes[loByte] & loMask)
+	for i := loByte + 1; i < hiByte; i++ {
+		count += bits.OnesCount64(bv.Bytes[i])
+	}
+
+	// hi can land exactly on the end of the last word
+	if hiBit != 0 {
+		hiMask := uint64(1)<<hiBit - 1
+		count += bits.OnesCount64(bv.Bytes[hiByte] & hiMask)
+	}
+
+	return count
+}
+
+// Or returns a new bitvec with every bit set in either bv or other. Its Size is
+// the bigger of the two.
+func (bv *Bitvec) Or(other *Bitvec) *Bitvec {
+	maxLen := max(len(other.Bytes), len(bv.Bytes))
+
+	result := &Bitvec{Bytes: make([]uint64, maxLen), Size: max(bv.Size, other.Size), Count: 0}
+	for i := range maxLen {
+		if i < len(bv.Bytes) {
+			result.Bytes[i] |= bv.Bytes[i]
+		}
+		if i < len(other.Bytes) {
+			result.Bytes[i] |= other.Bytes[i]
+		}
+		result.Count += bits.OnesCount64(result.Bytes[i])
+	}
+	return result
+}
+
+// FirstSet returns the index of the lowest set bit, or -1 if none are set
+func (bv *Bitvec) FirstSet() int {
+	return bv.NextSet(-1)
+}
+
+// NextSet returns the index of the lowest set bit after the given index, or -1
+// if there isn't one. Looping from FirstSet with NextSet visits every set bit
+// in increasing order without allocating.
+func (bv *Bitvec) NextSet(after int) int {
+	index := max(after+1, 0)
+	byteIndex := index / 64
+	if byteIndex >= len(bv.Bytes) {
+		return -1
+	}
+
+	// mask off the bits up to and including after in the first word
+	word := bv.Bytes[byteIndex] & (^uint64(0) << (index % 64))
+	for word == 0 {
+		byteIndex++
+		if byteIndex >= len(bv.Bytes) {
+			return -1
+		}
+		word = bv.Bytes[byteIndex]
+	}
+
+	return byteIndex*64 + bits.TrailingZeros64(word)
+}
+
+// maxStringIndices is how many set indices String lists before truncating
+const maxStringIndices = 10
+
+// String shows the size, count, and set indices, e.g.
+// Bitvec(size=2309,count=3,set=[12,88,1004]). Past maxStringIndices the list
+// is cut off with "..." so large vectors are still safe to log.
+func (bv *Bitvec) String() string {
+	var set strings.Builder
+	listed := 0
+	for i := bv.FirstSet(); i != -1; i = bv.NextSet(i) {
+		if listed == maxStringIndices {
+			set.WriteString(",...")
+			break
+		}
+		if listed > 0 {
+			set.WriteString(",")
+		}
+		set.WriteString(strconv.Itoa(i))
+		listed++
+	}
+
+	return fmt.Sprintf("Bitvec(size=%d,count=%d,set=[%s])", bv.Size, bv.Count, set.String())
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestCountInRange(t *testing.T) {
+	set := []int{0, 1, 5, 63, 64, 65, 100, 127, 128, 150, 191, 192, 199}
+	bv := NewBitvec(200)
+	for _, i := range set {
+		bv.Set(i)
+	}
+
+	tests := []struct {
+		lo, hi int
+		want   int
+	}{
+		{0, 200, len(set)},
+		{0, 0, 0},
+		{5, 5, 0},
+		{1, 6, 2},         // inside the first word
+		{3, 70, 4},        // mid-word to mid-word across one boundary
+		{60, 130, 6},      // across two boundaries
+		{64, 128, 4},      // exactly one word
+		{101, 199, 5},     // ends one short of the last bit
+		{-10, 300, 13},    // clamped to the vector
+		{150, 100, 0},     // empty range
+		{190, 200, 3},     // the partial last word
+		{127, 129, 2},     // straddles a boundary
+		{128, 128 + 1, 1}, // a single bit
+	}
+
+	for _, tt := range tests {
+		if got := bv.CountInRange(tt.lo, tt.hi); got != tt.want {
+			t.Errorf("CountInRange(%d, %d) = %d, want %d", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
+
+func TestCountInRangeMatchesGet(t *testing.T) {
+	bv := NewBitvec(200)
+	for i := 0; i < 200; i += 7 {
+		bv.Set(i)
+	}
+
+	for lo := range 200 {
+		for hi := lo; hi <= 200; hi++ {
+			want := 0
+			for i := lo; i < hi; i++ {
+				if bv.Get(i) {
+					want++
+				}
+			}
+			if got := bv.CountInRange(lo, hi); got != want {
+				t.Fatalf("CountInRange(%d, %d) = %d, want %d", lo, hi, got, want)
+			}
+		}
+	}
+}
+
+func TestFirstSetNextSet(t *testing.T) {
+	want := []int{3, 4, 63, 64, 130, 199}
+	bv := NewBitvec(200)
+	for _, i := range want {
+		bv.Set(i)
+	}
+
+	var got []int
+	for i := bv.FirstSet(); i != -1; i = bv.NextSet(i) {
+		if len(got) > 0 && i <= got[len(got)-1] {
+			t.Fatalf("NextSet went from %d to %d", got[len(got)-1], i)
+		}
+		got = append(got, i)
+		if len(got) > len(want) {
+			t.Fatalf("iterated past the set bits: %v", got)
+		}
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("iterated %v, want %v", got, want)
+	}
+
+	if first := NewBitvec(200).FirstSet(); first != -1 {
+		t.Errorf("FirstSet of an empty vector = %d, want -1", first)
+	}
+	if next := bv.NextSet(199); next != -1 {
+		t.Errorf("NextSet(199) = %d, want -1", next)
+	}
+}
+
+func TestBitvecString(t *testing.T) {
+	small := NewBitvec(2309)
+	for _, i := range []int{12, 88, 1004} {
+		small.Set(i)
+	}
+
+	large := NewBitvec(100)
+	for i := range 100 {
+		large.Set(i)
+	}
+
+	tests := []struct {
+		bv   *Bitvec
+		want string
+	}{
+		{NewBitvec(5), "Bitvec(size=5,count=0,set=[])"},
+		{small, "Bitvec(size=2309,count=3,set=[12,88,1004])"},
+		{large, "Bitvec(size=100,count=100,set=[0,1,2,3,4,5,6,7,8,9,...])"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.bv.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestGrow(t *testing.T) {
+	bv := NewBitvec(60)
+	bv.Set(2)
+	bv.Set(59)
+
+	bv.Grow(130)
+	if bv.Size != 130 || len(bv.Bytes) != 3 {
+		t.Fatalf("after Grow(130) Size = %d and %d words, want 130 and 3", bv.Size, len(bv.Bytes))
+	}
+
+	bv.Set(64)
+	bv.Set(129)
+	for _, i := range []int{2, 59, 64, 129} {
+		if !bv.Get(i) {
+			t.Errorf("bit %d isn't set", i)
+		}
+	}
+	if bv.Count != 4 || !bv.VerifyCount() {
+		t.Errorf("Count = %d, want 4", bv.Count)
+	}
+
+	bv.Grow(10)
+	if bv.Size != 130 {
+		t.Errorf("Grow(10) shrank Size to %d", bv.Size)
+	}
+}
+
+// randomBitvec returns a bitvec of size bits with about half of them set
+func randomBitvec(r *rand.Rand, size int) *Bitvec {
+	bv := NewBitvec(size)
+	for i := range size {
+		if r.IntN(2) == 0 {
+			bv.Set(i)
+		}
+	}
+
+	return bv
+}
+
+func TestAndIntoMatchesAnd(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	sizes := []int{1, 63, 64, 65, 200, 2309}
+
+	for _, size := range sizes {
+		for _, otherSize := range sizes {
+			a, b := randomBitvec(r, size), randomBitvec(r, otherSize)
+			want := a.And(b)
+
+			// a fresh dst, a dst with leftover bits that's too big, and dst
+			// aliasing each operand
+			fresh := &Bitvec{}
+			a.AndInto(fresh, b)
+			leftover := randomBitvec(r, 4000)
+			a.AndInto(leftover, b)
+			aliasA := &Bitvec{Bytes: slices.Clone(a.Bytes), Size: a.Size, Count: a.Count}
+			aliasA.AndInto(aliasA, b)
+			aliasB := &Bitvec{Bytes: slices.Clone(b.Bytes), Size: b.Size, Count: b.Count}
+			a.AndInto(aliasB, aliasB)
+
+			for name, got := range map[string]*Bitvec{"fresh": fresh, "leftover": leftover, "dst == bv": aliasA, "dst == other": aliasB} {
+				if !slices.Equal(got.Bytes, want.Bytes) || got.Size != want.Size || got.Count != want.Count {
+					t.Errorf("sizes %d & %d, %s: AndInto = %v, And = %v", size, otherSize, name, got, want)
+				}
+			}
+			if a.Intersects(b) != (want.Count > 0) {
+				t.Errorf("sizes %d & %d: Intersects = %v with %d bits in common", size, otherSize, a.Intersects(b), want.Count)
+			}
+		}
+	}
+}
+
+func TestAndIntoDoesNotAllocate(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	a, b := randomBitvec(r, 2309), randomBitvec(r, 2309)
+	dst := NewBitvec(2309)
+
+	if allocs := testing.AllocsPerRun(100, func() { a.AndInto(dst, b) }); allocs != 0 {
+		t.Errorf("AndInto made %v allocations, want 0", allocs)
+	}
+}
+
+func BenchmarkAnd(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 2))
+	x, y := randomBitvec(r, 2309), randomBitvec(r, 2309)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		x.And(y)
+	}
+}
+
+func BenchmarkAndInto(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 2))
+	x, y := randomBitvec(r, 2309), randomBitvec(r, 2309)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		scratch := getScratchBitvec()
+		x.AndInto(scratch, y)
+		putScratchBitvec(scratch)
+	}
+}
+
+func TestIsSubsetOf(t *testing.T) {
+	bitvec := func(size int, set ...int) *Bitvec {
+		bv := NewBitvec(size)
+		for _, i := range set {
+			bv.Set(i)
+		}
+		return bv
+	}
+
+	// stray bit past Size, like a bitvec whose Bytes came from somewhere else
+	stray := bitvec(70, 3, 65)
+	stray.Bytes[1] |= 1 << 20
+
+	tests := []struct {
+		name string
+		a, b *Bitvec
+		want bool
+	}{
+		{"proper subset", bitvec(200, 3, 130), bitvec(200, 3, 64, 130), true},
+		{"proper superset", bitvec(200, 3, 64, 130), bitvec(200, 3, 130), false},
+		{"equal", bitvec(200, 3, 64, 130), bitvec(200, 3, 64, 130), true},
+		{"disjoint", bitvec(200, 1, 2), bitvec(200, 3, 4), false},
+		{"overlapping", bitvec(200, 1, 2), bitvec(200, 2, 3), false},
+		{"empty", bitvec(200), bitvec(200, 5), true},
+		{"both empty", bitvec(200), bitvec(200), true},
+		{"shorter other", bitvec(200, 3, 130), bitvec(64, 3), false},
+		{"shorter other, in range", bitvec(200, 3), bitvec(64, 3), true},
+		{"bits past Size", stray, bitvec(70, 3, 65), true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.IsSubsetOf(tt.b); got != tt.want {
+			t.Errorf("%s: IsSubsetOf = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBoolSliceRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+
+	for _, size := range []int{0, 1, 5, 63, 64, 65, 100, 128, 2309} {
+		bits := make([]bool, size)
+		for i := range bits {
+			bits[i] = r.IntN(3) == 0
+		}
+
+		bv := BitvecFromBoolSlice(bits)
+		if bv.Size != size || !bv.VerifyCount() {
+			t.Errorf("size %d: got Size %d and Count %d", size, bv.Size, bv.Count)
+		}
+		for i, bit := range bits {
+			if bv.Get(i) != bit {
+				t.Errorf("size %d: bit %d = %v, want %v", size, i, bv.Get(i), bit)
+			}
+		}
+
+		if back := bv.ToBoolSlice(); !slices.Equal(back, bits) {
+			t.Errorf("size %d: round trip gave %v, want %v", size, back, bits)
+		}
+	}
+
+	// bits past Size don't show up
+	bv := NewBitvec(70)
+	bv.Set(69)
+	bv.Bytes[1] |= 1 << 20
+	if got := bv.ToBoolSlice(); len(got) != 70 || !got[69] || slices.Index(got, true) != 69 {
+		t.Errorf("ToBoolSlice() = %v", got)
+	}
+}
+
+func TestHash(t *testing.T) {
+	bitvec := func(size int, set ...int) *Bitvec {
+		bv := NewBitvec(size)
+		for _, i := range set {
+			bv.Set(i)
+		}
+		return bv
+	}
+
+	small, large := bitvec(70, 1, 64, 69), bitvec(2309, 1, 64, 69)
+	if small.Hash() != large.Hash() {
+		t.Errorf("same bits at sizes 70 and 2309 hash to %x and %x", small.Hash(), large.Hash())
+	}
+
+	// spare capacity and junk past Size don't count
+	roomy := &Bitvec{Bytes: make([]uint64, 2, 40), Size: 70}
+	for _, i := range []int{1, 64, 69} {
+		roomy.Set(i)
+	}
+	roomy.Bytes[1] |= 1 << 30
+	if roomy.Hash() != small.Hash() {
+		t.Errorf("extra capacity and bits past Size changed the hash")
+	}
+
+	distinct := []*Bitvec{bitvec(70), bitvec(70, 0), bitvec(70, 1), bitvec(70, 64), bitvec(70, 1, 64), small}
+	seen := make(map[uint64]*Bitvec)
+	for _, bv := range distinct {
+		if other, ok := seen[bv.Hash()]; ok {
+			t.Errorf("%v and %v hash the same", bv, other)
+		}
+		seen[bv.Hash()] = bv
+	}
+
+	// it's plain FNV-1a over the words, and cheap enough to call per lookup
+	want := fnv.New64a()
+	want.Write(binary.LittleEndian.AppendUint64(binary.LittleEndian.AppendUint64(nil, small.Bytes[0]), small.Bytes[1]))
+	if small.Hash() != want.Sum64() {
+		t.Errorf("Hash() = %x, want FNV-1a's %x", small.Hash(), want.Sum64())
+	}
+	if allocs := testing.AllocsPerRun(10, func() { large.Hash() }); allocs != 0 {
+		t.Errorf("Hash() made %v allocations", allocs)
+	}
+}
+
+func TestSetOutOfRange(t *testing.T) {
+	// 70 and 100 still fit in the last word, so only the check stops them
+	for _, index := range []int{70, 100, 128, -1} {
+		bv := NewBitvec(70)
+		func() {
+			defer func() {
+				want := fmt.Sprintf("Bitvec.Set: index %d out of range for size 70", index)
+				if r := recover(); r != want {
+					t.Errorf("Set(%d) panicked with %v, want %q", index, r, want)
+				}
+			}()
+			bv.Set(index)
+		}()
+		if bv.Count != 0 || slices.ContainsFunc(bv.Bytes, func(w uint64) bool { return w != 0 }) {
+			t.Errorf("Set(%d) changed the bitvec to %v", index, bv)
+		}
+	}
+
+	bv := NewBitvec(70)
+	bv.Set(69)
+	bv.Set(69)
+	if bv.Count != 1 {
+		t.Errorf("setting bit 69 twice gave Count %d", bv.Count)
+	}
+}
+
+func TestSetAll(t *testing.T) {
+	for _, size := range []int{0, 1, 7, 63, 64, 65, 127, 128, 200} {
+		bv := NewBitvec(size)
+		bv.SetAll()
+		if bv.Count != size || !bv.VerifyCount() {
+			t.Errorf("size %d: Count = %d, VerifyCount() = %v", size, bv.Count, bv.VerifyCount())
+		}
+		if size > 0 && bv.FirstSet() != 0 {
+			t.Errorf("size %d: FirstSet() = %d", size, bv.FirstSet())
+		}
+		for i := range size {
+			if !bv.Get(i) {
+				t.Fatalf("size %d: bit %d isn't set", size, i)
+			}
+		}
+		// nothing past Size, so And and Count stay exact
+		if size%64 != 0 && bv.Bytes[len(bv.Bytes)-1]>>(size%64) != 0 {
+			t.Errorf("size %d: bits past Size are set in %x", size, bv.Bytes[len(bv.Bytes)-1])
+		}
+	}
+
+	useFixture(t)
+	full := NewBitvec(len(answers))
+	full.SetAll()
+	for _, answer := range answers {
+		if got := full.And(lookupBitvec("crane", answer)); got.Count != lookupBitvec("crane", answer).Count {
+			t.Errorf("every answer & crane's bucket for %v has %d, want %d", answer, got.Count, lookupBitvec("crane", answer).Count)
+		}
+	}
+}
+
+func TestVerifyCountIgnoresTail(t *testing.T) {
+	// 70 bits is one full word and 6 bits of the next
+	bv := NewBitvec(70)
+	bv.Set(3)
+	bv.Set(69)
+
+	// stray bits past Size, like Hash and IsSubsetOf ignore
+	bv.Bytes[1] |= 1 << 20
+	if !bv.VerifyCount() {
+		t.Errorf("VerifyCount failed on %v with only bits past Size extra", bv)
+	}
+	clean := NewBitvec(70)
+	clean.Set(3)
+	clean.Set(69)
+	if bv.Hash() != clean.Hash() || !bv.IsSubsetOf(clean) {
+		t.Error("Hash or IsSubsetOf counted bits past Size")
+	}
+
+	bv.Count++
+	if bv.VerifyCount() {
+		t.Errorf("VerifyCount passed with Count %d", bv.Count)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// BestBlindSequence finds k guesses with pairwise disjoint letters that, when
+// all played regardless of hints, minimize the worst-case number of remaining
+// candidates. Ties go to list order. Returns the guesses and that worst-case
+// count.
+func BestBlindSequence(k int) ([]string, int) {
+	if k <= 0 {
+		return nil, len(answers)
+	}
+
+	fmt.Fprintf(os.Stderr, "Finding best blind sequence of %v guesses\n", k)
+
+	filteredGuesses, guessBitvecs := uniqueLetterGuesses()
+	if len(filteredGuesses) < k {
+		return nil, len(answers)
+	}
+
+	bar := newProgress(len(filteredGuesses))
+
+	// the best sequence starting with each guess, compared in order at the end
+	// so ties don't depend on which goroutine finishes first. bestVal is only
+	// shared to cut off the search early.
+	bestSeqs := make([][]string, len(filteredGuesses))
+	bestVals := make([]int, len(filteredGuesses))
+	bestVal := len(answers) + 1
+
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	workers := newWorkerLimit()
+
+	var search func(first int, seq []int, letters *Bitvec)
+	search = func(first int, seq []int, letters *Bitvec) {
+		if len(seq) == k {
+			words := make([]string, k)
+			for i, idx := range seq {
+				words[i] = filteredGuesses[idx]
+			}
+
+			// anything tying bestVal still has to be measured exactly, since
+			// it could come before the current best in list order
+			mu.Lock()
+			limit := min(bestVals[first], bestVal+1)
+			mu.Unlock()
+
+			val := worstCaseRemaining(words, limit)
+			if val >= limit {
+				return
+			}
+
+			bestVals[first] = val
+			bestSeqs[first] = words
+
+			mu.Lock()
+			if val < bestVal {
+				bestVal = val
+				bar.Describe(fmt.Sprintf("Best: %v (%v)", words, val))
+			}
+			mu.Unlock()
+			return
+		}
+
+		for j := seq[len(seq)-1] + 1; j < len(filteredGuesses); j++ {
+			if letters.Intersects(guessBitvecs[j]) {
+				continue
+			}
+			search(first, append(seq[:len(seq):len(seq)], j), letters.Or(guessBitvecs[j]))
+		}
+	}
+
+	for i := range filteredGuesses {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+			bestVals[i] = len(answers) + 1
+			search(i, []int{i}, guessBitvecs[i])
+			bar.Add(1)
+		}()
+	}
+
+	wg.Wait()
+
+	bestIdx := -1
+	for i := range bestSeqs {
+		if bestSeqs[i] != nil && (bestIdx == -1 || bestVals[i] < bestVals[bestIdx]) {
+			bestIdx = i
+		}
+	}
+	if bestIdx == -1 {
+		fmt.Fprintf(os.Stderr, "Done, no %v guesses have disjoint letters\n", k)
+		return nil, len(answers)
+	}
+
+	fmt.Fprintf(os.Stderr, "Done, best blind sequence: %v (%v)\n", bestSeqs[bestIdx], bestVals[bestIdx])
+	return bestSeqs[bestIdx], bestVals[bestIdx]
+}
+
+// worstCaseRemaining returns the largest number of candidates left over any
+// answer after playing all the given guesses. It stops early once the result
+// reaches limit, since the caller can't use anything that bad.
+func worstCaseRemaining(words []string, limit int) int {
+	worst := 0
+
+	scratch := getScratchBitvec()
+	defer putScratchBitvec(scratch)
+
+	for _, answer := range answers {
+		bitvec := lookupBitvec(words[0], answer)
+		for _, word := range words[1:] {
+			bitvec.AndInto(scratch, lookupBitvec(word, answer))
+			bitvec = scratch
+		}
+
+		worst = max(worst, bitvec.Count)
+		if worst >= limit {
+			break
+		}
+	}
+
+	return worst
+}
+
+// BestOpenerTriple extends findBestGuess to three guesses: out of every
+// triple of unique-letter guesses with no letters in common, it finds the one
+// with the lowest AvgNumCandidates when all three are played regardless of
+// hints. Ties go to list order. This checks a lot of triples on the full
+// lists, so expect it to take a while.
+func BestOpenerTriple() ([3]string, float64) {
+	fmt.Fprintf(os.Stderr, "Finding best opener triple\n")
+
+	filteredGuesses, guessBitvecs := uniqueLetterGuesses()
+	if len(filteredGuesses) < 3 {
+		return [3]string{}, 0
+	}
+
+	bar := newProgress(len(filteredGuesses) - 2)
+
+	// the best triple starting with each guess, compared in order at the end
+	// so ties don't depend on which goroutine finishes first
+	bestTriples := make([][3]string, len(filteredGuesses)-2)
+	bestVals := make([]float64, len(filteredGuesses)-2)
+
+	wg := sync.WaitGroup{}
+	limit := newWorkerLimit()
+
+	for i := range len(filteredGuesses) - 2 {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-limit }()
+
+			bestVals[i] = -1
+			for j := i + 1; j < len(filteredGuesses); j++ {
+				if guessBitvecs[i].Intersects(guessBitvecs[j]) {
+					continue
+				}
+				letters := guessBitvecs[i].Or(guessBitvecs[j])
+
+				for k := j + 1; k < len(filteredGuesses); k++ {
+					if letters.Intersects(guessBitvecs[k]) {
+						continue
+					}
+
+					triple := [3]string{filteredGuesses[i], filteredGuesses[j], filteredGuesses[k]}
+					val := AvgNumCandidates(triple[0], triple[1], triple[2])
+					if bestVals[i] == -1 || val < bestVals[i] {
+						bestTriples[i] = triple
+						bestVals[i] = val
+					}
+				}
+			}
+			bar.Add(1)
+		}()
+	}
+
+	wg.Wait()
+
+	bestIdx := -1
+	for i := range bestTriples {
+		if bestVals[i] != -1 && (bestIdx == -1 || bestVals[i] < bestVals[bestIdx]) {
+			bestIdx = i
+		}
+	}
+	if bestIdx == -1 {
+		fmt.Fprintf(os.Stderr, "Done, no three guesses have disjoint letters\n")
+		return [3]string{}, 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Done, best opener triple: %v (%.2f)\n", bestTriples[bestIdx], bestVals[bestIdx])
+	return bestTriples[bestIdx], bestVals[bestIdx]
+}
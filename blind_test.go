@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestBestBlindSequence(t *testing.T) {
+	useFixture(t)
+
+	seq, worst := BestBlindSequence(2)
+	guess1, guess2, _, err := bestGuessPair(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{guess1, guess2}; !slices.Equal(seq, want) {
+		t.Fatalf("BestBlindSequence(2) = %v, want %v like findBestGuess", seq, want)
+	}
+
+	// no disjoint pair does better
+	filtered, letters := uniqueLetterGuesses()
+	for i := range filtered {
+		for j := i + 1; j < len(filtered); j++ {
+			if letters[i].Intersects(letters[j]) {
+				continue
+			}
+			if val := worstCaseRemaining([]string{filtered[i], filtered[j]}, len(answers)+1); val < worst {
+				t.Errorf("%v, %v leaves at most %d, better than %d", filtered[i], filtered[j], val, worst)
+			}
+		}
+	}
+}
+
+func TestBestBlindSequenceIsDeterministic(t *testing.T) {
+	useFixture(t)
+
+	first, _ := BestBlindSequence(1)
+	for range 10 {
+		if seq, _ := BestBlindSequence(1); !slices.Equal(seq, first) {
+			t.Fatalf("BestBlindSequence(1) = %v, then %v", first, seq)
+		}
+	}
+}
+
+func TestBestOpenerTriple(t *testing.T) {
+	// the fixture has no three guesses with disjoint letters
+	useFixture(t)
+	if triple, val := BestOpenerTriple(); triple != [3]string{} || val != 0 {
+		t.Errorf("BestOpenerTriple() on the fixture = %v, %v, want nothing", triple, val)
+	}
+
+	// against the fixture answers, which no pair tells completely apart
+	useWordLists(t, "testdata/disjoint.txt", "testdata/answers.txt")
+	triple, val := BestOpenerTriple()
+
+	// check every disjoint triple, first in list order winning ties
+	filtered, letters := uniqueLetterGuesses()
+	var want [3]string
+	wantVal := -1.0
+	for i := range filtered {
+		for j := i + 1; j < len(filtered); j++ {
+			if letters[i].Intersects(letters[j]) {
+				continue
+			}
+			for k := j + 1; k < len(filtered); k++ {
+				if letters[i].Or(letters[j]).Intersects(letters[k]) {
+					continue
+				}
+				v := AvgNumCandidates(filtered[i], filtered[j], filtered[k])
+				if wantVal == -1 || v < wantVal {
+					want, wantVal = [3]string{filtered[i], filtered[j], filtered[k]}, v
+				}
+			}
+		}
+	}
+	if wantVal == -1 {
+		t.Fatal("testdata/disjoint.txt has no disjoint triples")
+	}
+	if triple != want || val != wantVal {
+		t.Errorf("BestOpenerTriple() = %v, %v, want %v, %v", triple, val, want, wantVal)
+	}
+
+	// a third blind guess can only split the pair's buckets further
+	guess1, guess2, pairVal, err := bestGuessPair(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val >= pairVal {
+		t.Errorf("BestOpenerTriple() leaves %v on average, no better than the pair %v, %v with %v", val, guess1, guess2, pairVal)
+	}
+}
@@ -0,0 +1,550 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// BitvecFromPredicate returns the answers that pred accepts
+func BitvecFromPredicate(pred func(word string) bool) *Bitvec {
+	bitvec := NewBitvec(len(answers))
+	for i, answer := range answers {
+		if pred(answer) {
+			bitvec.Set(i)
+		}
+	}
+
+	return bitvec
+}
+
+// GroupByHint returns the answers set in candidates grouped by the hint guess
+// would produce against each of them. The groups are empty if guess isn't a
+// known guess.
+func GroupByHint(guess string, candidates *Bitvec) map[Hint][]string {
+	groups := make(map[Hint][]string)
+
+	guessInfo := lookupGuessInfo(guess)
+	if guessInfo == nil {
+		return groups
+	}
+	answerHints := guessInfo.AnswerHints
+
+	for answerIdx, answer := range answers {
+		if !candidates.Get(answerIdx) {
+			continue
+		}
+		hint := answerHints[answer]
+		groups[hint] = append(groups[hint], answer)
+	}
+
+	return groups
+}
+
+// bucketCounts returns the number of candidates in each of guess's hint
+// buckets. A guess that isn't in the guess list has no buckets.
+func bucketCounts(guess string, candidates *Bitvec) map[Hint]int {
+	s := defaultSolver()
+	return s.bucketCounts(guess, candidates)
+}
+
+// ExpectedRemaining is the average number of candidates left after playing
+// guess, assuming each candidate is equally likely to be the answer
+func ExpectedRemaining(guess string, candidates *Bitvec) float64 {
+	s := defaultSolver()
+	return s.ExpectedRemaining(guess, candidates)
+}
+
+// Entropy is how many bits of information guess is expected to give about
+// which of candidates is the answer
+func Entropy(guess string, candidates *Bitvec) float64 {
+	// sum in a fixed order so guesses that split the same way get exactly the
+	// same entropy, whatever order the map hands the buckets back in
+	var bits float64
+	for _, count := range BucketSizes(guess, candidates) {
+		p := float64(count) / float64(candidates.Count)
+		bits -= p * math.Log2(p)
+	}
+
+	return bits
+}
+
+// BucketSizes returns the sizes of guess's nonempty hint buckets within
+// candidates, biggest first. Two guesses with the same sizes split the
+// candidates equally well by any measure that ignores which words go where.
+// Like GroupByHint, a guess that isn't in the guess list has no buckets.
+func BucketSizes(guess string, candidates *Bitvec) []int {
+	var sizes []int
+	for _, count := range bucketCounts(guess, candidates) {
+		sizes = append(sizes, count)
+	}
+	slices.SortFunc(sizes, func(a, b int) int { return b - a })
+
+	return sizes
+}
+
+// WorstCaseBucket is the size of guess's largest hint bucket within candidates
+func WorstCaseBucket(guess string, candidates *Bitvec) int {
+	s := defaultSolver()
+	return s.WorstCaseBucket(guess, candidates)
+}
+
+// SolveConservative picks the guess with the smallest worst-case bucket out of
+// the guesses whose expected remaining candidates is within epsilon of the
+// best. Ties go to the lower expected remaining, then to list order.
+func SolveConservative(candidates *Bitvec, epsilon float64) string {
+	s := defaultSolver()
+	return s.SolveConservative(candidates, epsilon)
+}
+
+// numGreens returns how many letters of h are green
+func (h Hint) numGreens() int {
+	greens := 0
+	for _, d := range h.Sequence() {
+		if d == 2 {
+			greens++
+		}
+	}
+
+	return greens
+}
+
+// AdversarialHint plays the host in Absurdle: it answers guess with whichever
+// hint keeps the most candidates alive, breaking ties toward fewer greens and
+// then the smaller hint. Returns that hint and the candidates left after it,
+// or 0 and candidates unchanged if guess isn't in the guess list.
+func AdversarialHint(guess string, candidates *Bitvec) (Hint, *Bitvec) {
+	var bestHint Hint
+	bestCount := -1
+
+	for hint, count := range bucketCounts(guess, candidates) {
+		if count > bestCount ||
+			(count == bestCount && hint.numGreens() < bestHint.numGreens()) ||
+			(count == bestCount && hint.numGreens() == bestHint.numGreens() && hint < bestHint) {
+			bestHint = hint
+			bestCount = count
+		}
+	}
+
+	if bestCount == -1 {
+		return bestHint, candidates
+	}
+
+	return bestHint, lookupGuessInfo(guess).hintBitvec(bestHint).And(candidates)
+}
+
+// BestGuessSampled is a faster, approximate version of picking the guess with
+// the lowest ExpectedRemaining. It only scores sampleSize guesses chosen at
+// random, so the cost scales with sampleSize instead of the whole guess list,
+// but it can miss the true best. The same seed always picks the same sample,
+// and a sampleSize covering every guess gives the exact answer. Ties go to
+// list order.
+func BestGuessSampled(candidates *Bitvec, sampleSize int, seed int64) string {
+	if candidates.Count == 0 || sampleSize <= 0 {
+		return ""
+	}
+
+	allowed := allowedGuesses()
+	if len(allowed) == 0 {
+		return ""
+	}
+	sample := rand.New(rand.NewPCG(uint64(seed), 0)).Perm(len(allowed))
+	sample = sample[:min(sampleSize, len(sample))]
+	slices.Sort(sample)
+
+	scores := make([]float64, len(sample))
+
+	wg := sync.WaitGroup{}
+
+	for i, guessIdx := range sample {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scores[i] = ExpectedRemaining(allowed[guessIdx], candidates)
+		}()
+	}
+
+	wg.Wait()
+
+	bestIdx := 0
+	for i := range sample {
+		if scores[i] < scores[bestIdx] {
+			bestIdx = i
+		}
+	}
+
+	return allowed[sample[bestIdx]]
+}
+
+// PerfectSplitters returns the guesses, in list order, that give a different
+// hint for every one of candidates, so the answer is known after playing any
+// of them
+func PerfectSplitters(candidates *Bitvec) []string {
+	allowed := allowedGuesses()
+	perfect := make([]bool, len(allowed))
+
+	wg := sync.WaitGroup{}
+
+	for i, guess := range allowed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			perfect[i] = WorstCaseBucket(guess, candidates) <= 1
+		}()
+	}
+
+	wg.Wait()
+
+	var splitters []string
+	for i, guess := range allowed {
+		if perfect[i] {
+			splitters = append(splitters, guess)
+		}
+	}
+
+	return splitters
+}
+
+// AnswersConsistentWith works backwards from a share grid: given each row's
+// guess and hint, it returns the answers that would have produced every row.
+// An all green row means the answer was that row's guess, even if it isn't in
+// the answer list. Returns nil if grid and guesses don't line up or a guess
+// is the wrong length, e.g. from a grid pasted from another variant.
+func AnswersConsistentWith(grid []Hint, guesses []string) []string {
+	if len(grid) != len(guesses) {
+		return nil
+	}
+	for _, guess := range guesses {
+		if checkWord(guess) != nil {
+			return nil
+		}
+	}
+
+	for i, hint := range grid {
+		if hint == allGreen() {
+			for j := range grid {
+				if getHint(guesses[j], guesses[i]) != grid[j] {
+					return nil
+				}
+			}
+			return []string{guesses[i]}
+		}
+	}
+
+	var consistent []string
+	for _, answer := range answers {
+		matches := true
+		for i, guess := range guesses {
+			if getHint(guess, answer) != grid[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			consistent = append(consistent, answer)
+		}
+	}
+
+	return consistent
+}
+
+// BestGuessHybrid picks the guess with the highest Entropy out of the guesses
+// whose expected remaining candidates is within epsilon of the best. Even
+// splits tend to need fewer guesses later. Ties go to list order.
+func BestGuessHybrid(candidates *Bitvec, epsilon float64) string {
+	if candidates.Count == 0 {
+		return ""
+	}
+
+	allowed := allowedGuesses()
+	if len(allowed) == 0 {
+		return ""
+	}
+	expected := make([]float64, len(allowed))
+
+	wg := sync.WaitGroup{}
+
+	for i, guess := range allowed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			expected[i] = ExpectedRemaining(guess, candidates)
+		}()
+	}
+
+	wg.Wait()
+
+	bestExpected := slices.Min(expected)
+
+	bestIdx := -1
+	bestEntropy := 0.0
+	for i, guess := range allowed {
+		if expected[i] > bestExpected+epsilon {
+			continue
+		}
+		entropy := Entropy(guess, candidates)
+		if bestIdx == -1 || entropy > bestEntropy {
+			bestIdx = i
+			bestEntropy = entropy
+		}
+	}
+
+	return allowed[bestIdx]
+}
+
+// PartitionOverlap counts the pairs of answers that g1 and g2 both tell apart.
+// The closer it is to what g1 tells apart on its own, the less g2 adds on top
+// of g1, which is why guess pairs with disjoint letters do well. Returns 0 if
+// either isn't a known guess.
+func PartitionOverlap(g1, g2 string) int {
+	info1, info2 := lookupGuessInfo(g1), lookupGuessInfo(g2)
+	if info1 == nil || info2 == nil {
+		return 0
+	}
+	hints1, hints2 := info1.AnswerHints, info2.AnswerHints
+
+	// pairs told apart by both = all pairs - pairs g1 can't tell apart
+	// - pairs g2 can't tell apart + pairs neither can tell apart
+	counts1 := make(map[Hint]int)
+	counts2 := make(map[Hint]int)
+	countsBoth := make(map[[2]Hint]int)
+	for _, answer := range answers {
+		counts1[hints1[answer]]++
+		counts2[hints2[answer]]++
+		countsBoth[[2]Hint{hints1[answer], hints2[answer]}]++
+	}
+
+	pairs := func(n int) int { return n * (n - 1) / 2 }
+
+	overlap := pairs(len(answers))
+	for _, n := range counts1 {
+		overlap -= pairs(n)
+	}
+	for _, n := range counts2 {
+		overlap -= pairs(n)
+	}
+	for _, n := range countsBoth {
+		overlap += pairs(n)
+	}
+
+	return overlap
+}
+
+// ScoredGuess is a guess and its ExpectedRemaining score
+type ScoredGuess struct {
+	Guess             string
+	ExpectedRemaining float64
+}
+
+// RankAllGuesses scores every allowed guess by ExpectedRemaining within
+// candidates and returns them best first. Ties stay in list order.
+func RankAllGuesses(candidates *Bitvec) []ScoredGuess {
+	allowed := allowedGuesses()
+	ranked := make([]ScoredGuess, len(allowed))
+
+	wg := sync.WaitGroup{}
+	limit := newWorkerLimit()
+
+	for i, guess := range allowed {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-limit }()
+			defer trackWorker()()
+			ranked[i] = ScoredGuess{guess, ExpectedRemaining(guess, candidates)}
+		}()
+	}
+
+	wg.Wait()
+
+	slices.SortStableFunc(ranked, func(a, b ScoredGuess) int {
+		return cmp.Compare(a.ExpectedRemaining, b.ExpectedRemaining)
+	})
+
+	return ranked
+}
+
+// RankAllGuessesStream is RankAllGuesses without the sorting: each guess's
+// score is sent as soon as it's computed, in no particular order, and the
+// channel is closed once every guess has been sent. Like RankAllGuesses, at
+// most MaxWorkers guesses are scored at once. The channel has to be drained;
+// use RankAllGuessesStreamContext to stop early.
+func RankAllGuessesStream(candidates *Bitvec) <-chan ScoredGuess {
+	return RankAllGuessesStreamContext(context.Background(), candidates)
+}
+
+// RankAllGuessesStreamContext is RankAllGuessesStream that can be cancelled.
+// Once ctx is done no more guesses get scored, and the channel is closed once
+// the workers that were already running have given up.
+func RankAllGuessesStreamContext(ctx context.Context, candidates *Bitvec) <-chan ScoredGuess {
+	allowed := allowedGuesses()
+	scored := make(chan ScoredGuess)
+
+	go func() {
+		defer close(scored)
+
+		wg := sync.WaitGroup{}
+		defer wg.Wait()
+		limit := newWorkerLimit()
+
+		for _, guess := range allowed {
+			select {
+			case limit <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-limit }()
+				defer trackWorker()()
+				if ctx.Err() != nil {
+					return
+				}
+
+				select {
+				case scored <- ScoredGuess{guess, ExpectedRemaining(guess, candidates)}:
+				case <-ctx.Done():
+				}
+			}()
+		}
+	}()
+
+	return scored
+}
+
+// Disambiguate returns the guesses that give a and b different hints, so
+// playing any of them tells the two apart. a and b come first when they're
+// allowed guesses, since guessing one of them might win outright, then the
+// rest in list order. Returns nil if either word is the wrong length.
+func Disambiguate(a, b string) []string {
+	if checkWord(a) != nil || checkWord(b) != nil {
+		return nil
+	}
+
+	var candidates, others []string
+	for _, guess := range allowedGuesses() {
+		if getHint(guess, a) == getHint(guess, b) {
+			continue
+		}
+		if guess == a || guess == b {
+			candidates = append(candidates, guess)
+		} else {
+			others = append(others, guess)
+		}
+	}
+
+	return append(candidates, others...)
+}
+
+// BestGuessPreferCommon picks the guess with the lowest ExpectedRemaining,
+// breaking ties toward the higher commonness score so players get a word
+// they know instead of an obscure one, then toward list order. Words missing
+// from commonness count as 0.
+func BestGuessPreferCommon(candidates *Bitvec, commonness map[string]int) string {
+	if candidates.Count == 0 {
+		return ""
+	}
+
+	ranked := RankAllGuesses(candidates)
+	if len(ranked) == 0 {
+		return ""
+	}
+
+	best := ranked[0]
+	for _, scored := range ranked[1:] {
+		if scored.ExpectedRemaining != ranked[0].ExpectedRemaining {
+			break
+		}
+		if commonness[scored.Guess] > commonness[best.Guess] {
+			best = scored
+		}
+	}
+
+	return best.Guess
+}
+
+// BestProbe picks the guess with the highest Entropy that can't be the answer,
+// i.e. isn't one of candidates. It never wins on the spot, but when there are
+// guesses to spare it can learn more than any candidate. Ties go to list
+// order. Returns "" if every allowed guess is a candidate.
+func BestProbe(candidates *Bitvec) string {
+	if candidates.Count == 0 {
+		return ""
+	}
+
+	isCandidate := make(map[string]bool, candidates.Count)
+	for i := candidates.FirstSet(); i != -1; i = candidates.NextSet(i) {
+		isCandidate[answers[i]] = true
+	}
+
+	var probes []string
+	for _, guess := range allowedGuesses() {
+		if !isCandidate[guess] {
+			probes = append(probes, guess)
+		}
+	}
+	if len(probes) == 0 {
+		return ""
+	}
+
+	entropies := make([]float64, len(probes))
+
+	wg := sync.WaitGroup{}
+
+	for i, guess := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entropies[i] = Entropy(guess, candidates)
+		}()
+	}
+
+	wg.Wait()
+
+	bestIdx := 0
+	for i := range probes {
+		if entropies[i] > entropies[bestIdx] {
+			bestIdx = i
+		}
+	}
+
+	return probes[bestIdx]
+}
+
+// FilterPartialHint returns the answers consistent with guess getting a hint
+// where only some tiles are known, e.g. from a blurry screenshot. Each of
+// knownDigits is 0, 1, or 2 like Sequence, or -1 if that tile is unknown.
+// The result is the union of the answers for every hint that matches.
+func FilterPartialHint(guess string, knownDigits [WordLen]int) *Bitvec {
+	matches := func(hint Hint) bool {
+		for i, d := range hint.Sequence() {
+			if knownDigits[i] != -1 && knownDigits[i] != d {
+				return false
+			}
+		}
+		return true
+	}
+
+	guessInfo := lookupGuessInfo(guess)
+	if guessInfo == nil {
+		// no precomputed bitvecs, so check every answer directly
+		return BitvecFromPredicate(func(answer string) bool {
+			return len(guess) == WordLen && matches(getHint(guess, answer))
+		})
+	}
+
+	filtered := NewBitvec(len(answers))
+	for hint := range guessInfo.HintsMap {
+		if matches(hint) {
+			filtered = filtered.Or(guessInfo.hintBitvec(hint))
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,569 @@
+package main
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupByHint(t *testing.T) {
+	useFixture(t)
+
+	candidates := fixtureBitvec(t, "crane", "crate", "grate", "irate", "light", "might", "tonal")
+	groups := GroupByHint("slate", candidates)
+
+	seen := make(map[string]bool)
+	for hint, words := range groups {
+		for _, word := range words {
+			if seen[word] {
+				t.Errorf("%q is in more than one group", word)
+			}
+			seen[word] = true
+			if got := getHint("slate", word); got != hint {
+				t.Errorf("%q is grouped under %v but gets %v", word, hint, got)
+			}
+		}
+	}
+
+	for i, answer := range answers {
+		if candidates.Get(i) != seen[answer] {
+			t.Errorf("%q: candidate %v, grouped %v", answer, candidates.Get(i), seen[answer])
+		}
+	}
+}
+
+func TestGroupByHintUnknownGuess(t *testing.T) {
+	useFixture(t)
+
+	if groups := GroupByHint("zzzzz", allAnswers()); len(groups) != 0 {
+		t.Errorf("GroupByHint for an unknown guess = %v, want no groups", groups)
+	}
+}
+
+func TestAdversarialHint(t *testing.T) {
+	useFixture(t)
+
+	candidates := allAnswers()
+	hint, left := AdversarialHint("fmnst", candidates)
+	if want := WorstCaseBucket("fmnst", candidates); left.Count != want {
+		t.Errorf("kept %d candidates, want the biggest bucket's %d", left.Count, want)
+	}
+	if want := lookupGuessInfo("fmnst").hintBitvec(hint).And(candidates); !left.IsSubsetOf(want) || !want.IsSubsetOf(left) {
+		t.Errorf("kept %v, want the %v bucket %v", left, hint, want)
+	}
+
+	// the greedy solver against the adversary, until it's forced to concede
+	adversarialGuesses := 0
+	for left := allAnswers(); ; {
+		guess, _ := chooseGuess(left)
+		hint, left = AdversarialHint(guess, left)
+		adversarialGuesses++
+		if hint == allGreen() {
+			break
+		}
+		if adversarialGuesses > len(answers) {
+			t.Fatal("never solved against the adversary")
+		}
+	}
+
+	fixedGuesses := 0
+	for _, answer := range answers {
+		fixedGuesses += len(SolveVerbose(answer))
+	}
+	mean := float64(fixedGuesses) / float64(len(answers))
+
+	if float64(adversarialGuesses) <= mean {
+		t.Errorf("took %d guesses against the adversary, no more than the %.2f average against fixed answers",
+			adversarialGuesses, mean)
+	}
+}
+
+func TestBestGuessSampled(t *testing.T) {
+	useFixture(t)
+
+	candidates := allAnswers()
+	exact := guesses[0]
+	for _, guess := range guesses {
+		if ExpectedRemaining(guess, candidates) < ExpectedRemaining(exact, candidates) {
+			exact = guess
+		}
+	}
+	if got := BestGuessSampled(candidates, len(guesses), 1); got != exact {
+		t.Errorf("sampling every guess picked %v, want %v", got, exact)
+	}
+
+	first := BestGuessSampled(candidates, 5, 42)
+	for range 5 {
+		if got := BestGuessSampled(candidates, 5, 42); got != first {
+			t.Fatalf("the same seed picked %v, then %v", first, got)
+		}
+	}
+}
+
+func TestBitvecFromPredicate(t *testing.T) {
+	useFixture(t)
+
+	hasA := func(word string) bool { return strings.ContainsRune(word, 'a') }
+	bitvec := BitvecFromPredicate(hasA)
+
+	want := 0
+	for i, answer := range answers {
+		if hasA(answer) {
+			want++
+		}
+		if bitvec.Get(i) != hasA(answer) {
+			t.Errorf("%q: set %v, contains a %v", answer, bitvec.Get(i), hasA(answer))
+		}
+	}
+	if bitvec.Count != want {
+		t.Errorf("Count = %d, want %d", bitvec.Count, want)
+	}
+}
+
+func TestPerfectSplitters(t *testing.T) {
+	useFixture(t)
+
+	// the answers fmnst can't tell apart
+	candidates := fixtureBitvec(t, "crate", "grate", "irate", "taker")
+	splitters := PerfectSplitters(candidates)
+
+	if !slices.Contains(splitters, "tight") {
+		t.Errorf("tight gives each a different hint but isn't in %v", splitters)
+	}
+	if slices.Contains(splitters, "fmnst") {
+		t.Errorf("fmnst is in %v", splitters)
+	}
+	for _, guess := range guesses {
+		perfect := len(GroupByHint(guess, candidates)) == candidates.Count
+		if perfect != slices.Contains(splitters, guess) {
+			t.Errorf("%v: %d hints for %d candidates, but in PerfectSplitters = %v",
+				guess, len(GroupByHint(guess, candidates)), candidates.Count, !perfect)
+		}
+	}
+}
+
+func TestAnswersConsistentWith(t *testing.T) {
+	useFixture(t)
+
+	// light's game from SolveVerbose
+	played := []string{"fmnst", "taker", "light"}
+	var grid []Hint
+	for _, guess := range played {
+		grid = append(grid, getHint(guess, "light"))
+	}
+
+	if got := AnswersConsistentWith(grid, played); !slices.Equal(got, []string{"light"}) {
+		t.Errorf("full grid gave %v, want [light]", got)
+	}
+
+	// without the winning row it could have been wight too
+	if got := AnswersConsistentWith(grid[:2], played[:2]); !slices.Equal(got, []string{"light", "wight"}) {
+		t.Errorf("first two rows gave %v, want [light wight]", got)
+	}
+
+	// an all green row that contradicts an earlier one
+	if got := AnswersConsistentWith([]Hint{allGreen(), allGreen()}, []string{"fmnst", "light"}); got != nil {
+		t.Errorf("contradictory grid gave %v", got)
+	}
+	if got := AnswersConsistentWith(grid, played[:2]); got != nil {
+		t.Errorf("mismatched lengths gave %v", got)
+	}
+	for _, guesses := range [][]string{{"cranes"}, {"cat"}} {
+		if got := AnswersConsistentWith([]Hint{allGreen()}, guesses); got != nil {
+			t.Errorf("AnswersConsistentWith(%v) = %v, want nil", guesses, got)
+		}
+		if got := AnswersConsistentWith([]Hint{0}, guesses); got != nil {
+			t.Errorf("AnswersConsistentWith(%v) = %v, want nil", guesses, got)
+		}
+	}
+}
+
+func TestBestGuessHybrid(t *testing.T) {
+	useFixture(t)
+	candidates := allAnswers()
+
+	// fmnst and tonal both leave 2.5 candidates on average, but tonal's
+	// buckets are more even
+	if ExpectedRemaining("fmnst", candidates) != ExpectedRemaining("tonal", candidates) {
+		t.Fatalf("fmnst and tonal expect %v and %v remaining, want them equal",
+			ExpectedRemaining("fmnst", candidates), ExpectedRemaining("tonal", candidates))
+	}
+	if Entropy("tonal", candidates) <= Entropy("fmnst", candidates) {
+		t.Fatalf("tonal has %v bits, not more than fmnst's %v", Entropy("tonal", candidates), Entropy("fmnst", candidates))
+	}
+	for _, guess := range guesses {
+		if ExpectedRemaining(guess, candidates) < ExpectedRemaining("tonal", candidates) {
+			t.Fatalf("%v expects fewer remaining than tonal", guess)
+		}
+	}
+
+	if got := BestGuessHybrid(candidates, 0); got != "tonal" {
+		t.Errorf("BestGuessHybrid(all, 0) = %v, want tonal", got)
+	}
+
+	// with everything in range it's just the highest entropy
+	best := guesses[0]
+	for _, guess := range guesses {
+		if Entropy(guess, candidates) > Entropy(best, candidates) {
+			best = guess
+		}
+	}
+	if got := BestGuessHybrid(candidates, 100); got != best {
+		t.Errorf("BestGuessHybrid(all, 100) = %v, want %v", got, best)
+	}
+}
+
+func TestPartitionOverlap(t *testing.T) {
+	useFixture(t)
+
+	// count the pairs directly
+	bothSeparate := func(g1, g2 string) int {
+		n := 0
+		for i, a := range answers {
+			for _, b := range answers[i+1:] {
+				if getHint(g1, a) != getHint(g1, b) && getHint(g2, a) != getHint(g2, b) {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	for _, g1 := range guesses {
+		for _, g2 := range guesses {
+			if got, want := PartitionOverlap(g1, g2), bothSeparate(g1, g2); got != want {
+				t.Errorf("PartitionOverlap(%v, %v) = %d, want %d", g1, g2, got, want)
+			}
+		}
+	}
+
+	// maker only differs from baker in one letter, so it tells apart nearly
+	// the same pairs; fmnst shares no letters with baker and adds more
+	self := PartitionOverlap("baker", "baker")
+	same, disjoint := PartitionOverlap("baker", "maker"), PartitionOverlap("baker", "fmnst")
+	if !(disjoint < same && same < self) {
+		t.Errorf("overlap with baker: fmnst %d, maker %d, itself %d; want increasing", disjoint, same, self)
+	}
+
+	if got := PartitionOverlap("baker", "zzzzz"); got != 0 {
+		t.Errorf("PartitionOverlap with an unknown guess = %d, want 0", got)
+	}
+}
+
+func TestRankAllGuesses(t *testing.T) {
+	useFixture(t)
+
+	candidates := fixtureBitvec(t, "light", "might", "night", "right", "sight", "tight", "wight", "fight")
+	ranked := RankAllGuesses(candidates)
+	if len(ranked) != len(guesses) {
+		t.Fatalf("ranked %d guesses, want %d", len(ranked), len(guesses))
+	}
+
+	seen := make(map[string]bool)
+	for i, scored := range ranked {
+		if want := ExpectedRemaining(scored.Guess, candidates); scored.ExpectedRemaining != want {
+			t.Errorf("%v scored %v, want %v", scored.Guess, scored.ExpectedRemaining, want)
+		}
+		if i > 0 && scored.ExpectedRemaining < ranked[i-1].ExpectedRemaining {
+			t.Errorf("%v (%v) is ranked after %v (%v)", scored.Guess, scored.ExpectedRemaining,
+				ranked[i-1].Guess, ranked[i-1].ExpectedRemaining)
+		}
+		seen[scored.Guess] = true
+	}
+	if len(seen) != len(guesses) {
+		t.Errorf("only %d different guesses were ranked", len(seen))
+	}
+}
+
+func TestRequiredLetters(t *testing.T) {
+	useFixture(t)
+	setForTest(t, &RequiredLetters, []byte{'s', 't'})
+
+	hasBoth := func(word string) bool { return strings.ContainsRune(word, 's') && strings.ContainsRune(word, 't') }
+	var want []string
+	for _, guess := range guesses {
+		if hasBoth(guess) {
+			want = append(want, guess)
+		}
+	}
+
+	ranked := RankAllGuesses(allAnswers())
+	var got []string
+	for _, scored := range ranked {
+		got = append(got, scored.Guess)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("RankAllGuesses ranked %v, want %v", got, want)
+	}
+
+	candidates := fixtureBitvec(t, "light", "might", "night", "right", "fight", "tight", "wight")
+	for _, guess := range []string{SolveConservative(candidates, 0), BestGuessHybrid(candidates, 0), BestProbe(candidates)} {
+		if !hasBoth(guess) {
+			t.Errorf("recommended %v", guess)
+		}
+	}
+
+	// neither of the last two candidates has an s, so the solvers can't just
+	// guess one of them
+	lastTwo := fixtureBitvec(t, "light", "might")
+	if guess, _ := chooseGuess(lastTwo); !hasBoth(guess) {
+		t.Errorf("chooseGuess(light, might) = %v", guess)
+	}
+	if guess, err := (&Game{candidates: lastTwo}).NextGuess(); err == nil && !hasBoth(guess) {
+		t.Errorf("NextGuess() with light and might left = %v", guess)
+	}
+
+	// openers without both letters are rejected like unknown ones
+	if got := HardestAnswers("crane", 1); got != nil {
+		t.Errorf("HardestAnswers(crane) = %v, want nil", got)
+	}
+	if got := UnresolvableWithin("crane", 6); got != nil {
+		t.Errorf("UnresolvableWithin(crane) = %v, want nil", got)
+	}
+	if err := ExportSolvePaths("crane", io.Discard); err == nil {
+		t.Error("ExportSolvePaths(crane) didn't return an error")
+	}
+
+	// no guess has a q
+	RequiredLetters = []byte{'q', 't'}
+	if guess := SolveConservative(candidates, 0); guess != "" {
+		t.Errorf("recommended %v with a q required", guess)
+	}
+
+	// the game level solvers have nothing to play either, but mustn't crash
+	if guess, err := NewGame().NextGuess(); err == nil {
+		t.Errorf("NextGuess() = %v with a q required", guess)
+	}
+	if steps := SolveVerbose("crate"); len(steps) != 0 {
+		t.Errorf("SolveVerbose(crate) played %v with a q required", steps)
+	}
+	numGuesses, failures := SimulateAll()
+	if len(numGuesses) != 0 || !slices.Equal(failures, answers) {
+		t.Errorf("SimulateAll() = %v, %v, want every answer failed", numGuesses, failures)
+	}
+	if hardest := HardestAnswers("fmnst", 1); hardest != nil {
+		t.Errorf("HardestAnswers(fmnst) = %v with a q required", hardest)
+	}
+}
+
+func TestRankAllGuessesStream(t *testing.T) {
+	useFixture(t)
+	candidates := allAnswers()
+
+	seen := make(map[string]int)
+	for scored := range RankAllGuessesStream(candidates) {
+		seen[scored.Guess]++
+		if want := ExpectedRemaining(scored.Guess, candidates); scored.ExpectedRemaining != want {
+			t.Errorf("%v scored %v, want %v", scored.Guess, scored.ExpectedRemaining, want)
+		}
+	}
+
+	if len(seen) != len(guesses) {
+		t.Errorf("streamed %d different guesses, want %d", len(seen), len(guesses))
+	}
+	for guess, n := range seen {
+		if n != 1 {
+			t.Errorf("%v was sent %d times", guess, n)
+		}
+	}
+
+	// a reader that stops after one guess
+	setForTest(t, &MaxWorkers, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := RankAllGuessesStreamContext(ctx, candidates)
+	<-stream
+	cancel()
+
+	done := make(chan int)
+	go func() {
+		rest := 0
+		for range stream {
+			rest++
+		}
+		done <- rest
+	}()
+	select {
+	case rest := <-done:
+		// the one worker may already have had its score ready
+		if rest > 1 {
+			t.Errorf("%d more guesses were sent after cancelling", rest)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream wasn't closed after cancelling")
+	}
+}
+
+func TestDisambiguate(t *testing.T) {
+	useFixture(t)
+
+	// only the first letter differs, so it comes down to who has an l or an m
+	want := []string{"light", "might", "fmnst", "maker", "slate", "stale", "steal", "tonal", "zonal"}
+	if got := Disambiguate("light", "might"); !slices.Equal(got, want) {
+		t.Errorf("Disambiguate(light, might) = %v, want %v", got, want)
+	}
+
+	got := Disambiguate("stale", "steal")
+	if len(got) < 2 || got[0] != "stale" || got[1] != "steal" {
+		t.Errorf("Disambiguate(stale, steal) = %v, want stale and steal first", got)
+	}
+	for _, guess := range guesses {
+		differs := getHint(guess, "stale") != getHint(guess, "steal")
+		if slices.Contains(got, guess) != differs {
+			t.Errorf("%v gives different hints: %v, but returned: %v", guess, differs, slices.Contains(got, guess))
+		}
+	}
+
+	if got := Disambiguate("cat", "steal"); got != nil {
+		t.Errorf("Disambiguate(cat, steal) = %v, want nil", got)
+	}
+}
+
+func TestBestGuessPreferCommon(t *testing.T) {
+	useFixture(t)
+	candidates := allAnswers()
+
+	// fmnst and tonal tie for the lowest expected remaining
+	if ExpectedRemaining("fmnst", candidates) != ExpectedRemaining("tonal", candidates) {
+		t.Fatalf("fmnst and tonal no longer tie")
+	}
+
+	tests := []struct {
+		commonness map[string]int
+		want       string
+	}{
+		{nil, "fmnst"},
+		{map[string]int{"tonal": 5}, "tonal"},
+		{map[string]int{"fmnst": 1, "tonal": 5}, "tonal"},
+		{map[string]int{"fmnst": 5, "tonal": 5}, "fmnst"},
+		// only breaks ties, however common a worse guess is
+		{map[string]int{"crane": 100}, "fmnst"},
+	}
+	for _, tt := range tests {
+		if got := BestGuessPreferCommon(candidates, tt.commonness); got != tt.want {
+			t.Errorf("BestGuessPreferCommon with %v = %v, want %v", tt.commonness, got, tt.want)
+		}
+	}
+
+	if got := BestGuessPreferCommon(NewBitvec(len(answers)), nil); got != "" {
+		t.Errorf("BestGuessPreferCommon with no candidates = %q", got)
+	}
+}
+
+func TestBucketSizes(t *testing.T) {
+	useFixture(t)
+
+	if got := BucketSizes("tight", fixtureBitvec(t, "crate", "grate", "irate", "taker")); !slices.Equal(got, []int{1, 1, 1, 1}) {
+		t.Errorf("BucketSizes(tight) after fmnst = %v, want a perfect split", got)
+	}
+
+	candidates := allAnswers()
+	for _, guess := range guesses {
+		counts := make(map[Hint]int)
+		for _, answer := range answers {
+			counts[getHint(guess, answer)]++
+		}
+		var want []int
+		for _, count := range counts {
+			want = append(want, count)
+		}
+		slices.Sort(want)
+		slices.Reverse(want)
+
+		if got := BucketSizes(guess, candidates); !slices.Equal(got, want) {
+			t.Errorf("BucketSizes(%v) = %v, want %v", guess, got, want)
+		}
+	}
+
+	// the same sizes give exactly the same entropy
+	candidates = fixtureBitvec(t, "baker", "maker", "taker")
+	if !slices.Equal(BucketSizes("fmnst", candidates), BucketSizes("might", candidates)) ||
+		Entropy("fmnst", candidates) != Entropy("might", candidates) {
+		t.Errorf("fmnst and might split baker, maker, taker as %v and %v", BucketSizes("fmnst", candidates), BucketSizes("might", candidates))
+	}
+
+	for _, guess := range []string{"zzzzz", "cat"} {
+		if got := BucketSizes(guess, candidates); len(got) != 0 || len(GroupByHint(guess, candidates)) != 0 {
+			t.Errorf("BucketSizes(%q) = %v, want no buckets like GroupByHint", guess, got)
+		}
+	}
+}
+
+func TestBestProbe(t *testing.T) {
+	useFixture(t)
+
+	tests := []struct {
+		candidates []string
+		want       string
+	}{
+		// tonal splits every answer best but could be the answer
+		{answers, "fmnst"},
+		{[]string{"crate", "grate", "irate", "taker"}, "tight"},
+		// fmnst and might tie, so list order decides
+		{[]string{"baker", "maker", "taker"}, "fmnst"},
+	}
+	for _, tt := range tests {
+		candidates := fixtureBitvec(t, tt.candidates...)
+		got := BestProbe(candidates)
+		if got != tt.want {
+			t.Errorf("BestProbe(%v) = %v, want %v", tt.candidates, got, tt.want)
+		}
+		if slices.Contains(tt.candidates, got) {
+			t.Errorf("BestProbe(%v) = %v, which is a candidate", tt.candidates, got)
+		}
+	}
+
+	if got := Entropy("tonal", allAnswers()); got <= Entropy("fmnst", allAnswers()) {
+		t.Fatalf("tonal has %v bits, no more than fmnst's %v", got, Entropy("fmnst", allAnswers()))
+	}
+
+	// nothing to probe with when every allowed guess is a candidate
+	setForTest(t, &GuessesFromAnswersOnly, true)
+	if got := BestProbe(allAnswers()); got != "" {
+		t.Errorf("BestProbe with only answers allowed = %v, want \"\"", got)
+	}
+	if got := BestProbe(NewBitvec(len(answers))); got != "" {
+		t.Errorf("BestProbe with no candidates = %v, want \"\"", got)
+	}
+}
+
+func TestFilterPartialHint(t *testing.T) {
+	useFixture(t)
+
+	unknown := [WordLen]int{-1, -1, -1, -1, -1}
+	// ghost isn't a guess, so it's checked without bitvecs
+	for _, guess := range []string{"crane", "fmnst", "ghost"} {
+		for _, known := range [][WordLen]int{
+			unknown,
+			{-1, -1, 2, 2, 2},
+			{0, -1, -1, -1, 1},
+			getHint(guess, "tight").Sequence(),
+		} {
+			got := FilterPartialHint(guess, known)
+			want := 0
+			for i, answer := range answers {
+				matches := true
+				for j, d := range getHint(guess, answer).Sequence() {
+					if known[j] != -1 && known[j] != d {
+						matches = false
+					}
+				}
+				if matches {
+					want++
+				}
+				if got.Get(i) != matches {
+					t.Errorf("FilterPartialHint(%v, %v) has %v: %v, want %v", guess, known, answer, got.Get(i), matches)
+				}
+			}
+			if got.Count != want {
+				t.Errorf("FilterPartialHint(%v, %v) counts %d, want %d", guess, known, got.Count, want)
+			}
+		}
+	}
+
+	if got := FilterPartialHint("fmnst", unknown); got.Count != len(answers) {
+		t.Errorf("with every tile unknown %d answers are left, want all %d", got.Count, len(answers))
+	}
+}
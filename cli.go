@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// numSuggestions is how many guesses the suggest command lists
+const numSuggestions = 10
+
+// runCommand runs a subcommand from the command line, writing its output to w
+func runCommand(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: go-wordle-solving <command> [args]")
+	}
+
+	switch args[0] {
+	case "suggest":
+		return runSuggest(args[1:], w)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// Suggestion is a recommended guess and its Entropy against the candidates
+type Suggestion struct {
+	Word  string  `json:"word"`
+	Score float64 `json:"score"`
+}
+
+// runSuggest prints the best guesses given the history so far, passed as
+// word:hint arguments like crane:⬜🟨⬜🟩⬜ or crane:01020
+func runSuggest(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("suggest", flag.ContinueOnError)
+	flags.SetOutput(w)
+	asJSON := flags.Bool("json", false, "print suggestions as a JSON array")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var history []GuessResult
+	for _, arg := range flags.Args() {
+		result, err := parseGuessArg(arg)
+		if err != nil {
+			return err
+		}
+		history = append(history, result)
+	}
+
+	g, err := NewGameWithHints(history)
+	if err != nil {
+		return err
+	}
+	suggestions := Suggest(g, numSuggestions)
+
+	if *asJSON {
+		return json.NewEncoder(w).Encode(suggestions)
+	}
+
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "%v %.3f\n", s.Word, s.Score)
+	}
+	return nil
+}
+
+// parseGuessArg parses a word:hint argument
+func parseGuessArg(arg string) (GuessResult, error) {
+	word, hintStr, ok := strings.Cut(arg, ":")
+	if !ok {
+		return GuessResult{}, fmt.Errorf("%q should look like word:hint", arg)
+	}
+	word = strings.ToLower(word)
+	if err := checkWord(word); err != nil {
+		return GuessResult{}, err
+	}
+	if !IsLegalGuess(word) {
+		return GuessResult{}, fmt.Errorf("%q isn't in the guess list", word)
+	}
+
+	digits, err := hintDigits(hintStr)
+	if err != nil {
+		return GuessResult{}, err
+	}
+	hint, err := hintFromDigits(digits)
+	if err != nil {
+		return GuessResult{}, err
+	}
+
+	return GuessResult{word, hint}, nil
+}
+
+// Suggest returns the n guesses with the highest Entropy against g's
+// candidates, best first. Ties go to guesses that could be the answer, then
+// to list order.
+func Suggest(g *Game, n int) []Suggestion {
+	if g.candidates.Count == 0 {
+		return nil
+	}
+
+	allowed := allowedGuesses()
+	suggestions := make([]Suggestion, len(allowed))
+
+	wg := sync.WaitGroup{}
+
+	for i, guess := range allowed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			suggestions[i] = Suggestion{guess, Entropy(guess, g.candidates)}
+		}()
+	}
+
+	wg.Wait()
+
+	remaining := NewWordSet(answers)
+	for _, word := range g.Remaining() {
+		remaining.Add(word)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return remaining.Contains(suggestions[i].Word) && !remaining.Contains(suggestions[j].Word)
+	})
+
+	return suggestions[:min(n, len(suggestions))]
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRunSuggestJSON(t *testing.T) {
+	useFixture(t)
+
+	// upper case is fine, the guess list is lower case
+	var out strings.Builder
+	if err := runCommand([]string{"suggest", "--json", "FMNST:⬜⬜⬜⬜🟨"}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var suggestions []Suggestion
+	if err := json.Unmarshal([]byte(out.String()), &suggestions); err != nil {
+		t.Fatalf("output isn't a JSON array of suggestions: %v\n%s", err, out.String())
+	}
+	if len(suggestions) != numSuggestions {
+		t.Fatalf("got %d suggestions, want %d", len(suggestions), numSuggestions)
+	}
+	for i := 1; i < len(suggestions); i++ {
+		if suggestions[i].Score > suggestions[i-1].Score {
+			t.Errorf("%v scores %v, more than %v before it", suggestions[i].Word, suggestions[i].Score, suggestions[i-1].Score)
+		}
+	}
+	// crate, grate, irate, and taker are left, and tight tells them all apart
+	if suggestions[0].Score != 2 {
+		t.Errorf("best suggestion %v scores %v, want 2 bits", suggestions[0].Word, suggestions[0].Score)
+	}
+}
+
+func TestMainSuggestJSON(t *testing.T) {
+	// the cache status and progress go to stderr, so stdout is nothing but
+	// the JSON
+	stdout, stderr, err := runMain(t, nil, "suggest", "--json", "fmnst:⬜⬜⬜⬜🟨")
+	if err != nil {
+		t.Fatalf("%v\n%s", err, stderr)
+	}
+
+	var suggestions []Suggestion
+	if err := json.Unmarshal([]byte(stdout), &suggestions); err != nil {
+		t.Fatalf("stdout isn't a JSON array of suggestions: %v\n%s", err, stdout)
+	}
+	if len(suggestions) != numSuggestions {
+		t.Errorf("got %d suggestions, want %d", len(suggestions), numSuggestions)
+	}
+}
+
+func TestRunSuggestErrors(t *testing.T) {
+	useFixture(t)
+
+	bad := [][]string{
+		{"suggest", "fmnst"},
+		{"suggest", "zzzzz:00000"},
+		{"suggest", "fmnst:0000"},
+		{"suggest", "fmnst:0000x"},
+		{"suggest", "--nope"},
+		{"solve"},
+		{},
+	}
+	for _, args := range bad {
+		var out strings.Builder
+		if err := runCommand(args, &out); err == nil {
+			t.Errorf("runCommand(%q) didn't return an error", args)
+		}
+	}
+}
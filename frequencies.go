@@ -0,0 +1,148 @@
+package main
+
+// PositionalFrequencies counts how often each letter appears in each position
+// across the answer list, indexed by letterIndex. Bytes outside Alphabet
+// aren't counted.
+func PositionalFrequencies() [WordLen][]int {
+	var freqs [WordLen][]int
+	for i := range freqs {
+		freqs[i] = make([]int, len(Alphabet))
+	}
+
+	for _, answer := range answers {
+		for i := range WordLen {
+			if j := letterIndex(answer[i]); j != -1 {
+				freqs[i][j]++
+			}
+		}
+	}
+
+	return freqs
+}
+
+// LetterFrequencies counts how many answers contain each letter, indexed by
+// letterIndex. A letter that appears twice in one answer is only counted once.
+func LetterFrequencies() []int {
+	freqs := make([]int, len(Alphabet))
+
+	for _, answer := range answers {
+		seen := make([]bool, len(Alphabet))
+		for i := range WordLen {
+			if j := letterIndex(answer[i]); j != -1 {
+				seen[j] = true
+			}
+		}
+		for j, ok := range seen {
+			if ok {
+				freqs[j]++
+			}
+		}
+	}
+
+	return freqs
+}
+
+// LetterTables is LetterFrequencies and PositionalFrequencies counted once,
+// so scoring many guesses doesn't go over the answer list for each one
+type LetterTables struct {
+	Letters    []int
+	Positional [WordLen][]int
+}
+
+// NewLetterTables counts the letter tables for the current answer list
+func NewLetterTables() *LetterTables {
+	return &LetterTables{LetterFrequencies(), PositionalFrequencies()}
+}
+
+// ScoreByLetterFrequency is a cheap heuristic that doesn't need the cache:
+// the sum of the answer frequencies of the distinct letters in guess, plus the
+// positional frequency of each letter where it sits. Higher is better. It
+// counts the answers on every call, so use LetterTables.Score to score more
+// than one guess.
+func ScoreByLetterFrequency(guess string) int {
+	return NewLetterTables().Score(guess)
+}
+
+// Score is ScoreByLetterFrequency using t. Bytes that aren't letters from
+// Alphabet, and anything past WordLen, count for nothing, so check user input
+// with checkWord first.
+func (t *LetterTables) Score(guess string) int {
+	score := 0
+	seen := make([]bool, len(Alphabet))
+	for i := range min(len(guess), WordLen) {
+		j := letterIndex(guess[i])
+		if j == -1 {
+			continue
+		}
+		score += t.Positional[i][j]
+		if !seen[j] {
+			seen[j] = true
+			score += t.Letters[j]
+		}
+	}
+
+	return score
+}
+
+// CoverageScore is the sum of the answer frequencies of every distinct letter
+// covered by words, so common letters count for more and repeats count once.
+// Anything that isn't a letter from Alphabet is ignored.
+func CoverageScore(words ...string) int {
+	return coverageScore(LetterFrequencies(), words...)
+}
+
+// coverageScore is CoverageScore with the letter frequencies already counted
+func coverageScore(letterFreqs []int, words ...string) int {
+	seen := make([]bool, len(Alphabet))
+	for _, word := range words {
+		for i := range len(word) {
+			if j := letterIndex(word[i]); j != -1 {
+				seen[j] = true
+			}
+		}
+	}
+
+	score := 0
+	for j, ok := range seen {
+		if ok {
+			score += letterFreqs[j]
+		}
+	}
+
+	return score
+}
+
+// BestCoveragePair is a cheap stand-in for findBestGuess: the pair of guesses
+// with no letters in common that has the highest CoverageScore
+func BestCoveragePair() (string, string) {
+	filteredGuesses, guessBitvecs := uniqueLetterGuesses()
+	if len(filteredGuesses) < 2 {
+		return "", ""
+	}
+
+	// letters are disjoint within a pair, so a pair's score is just the sum of
+	// the two words' scores
+	letterFreqs := LetterFrequencies()
+	scores := make([]int, len(filteredGuesses))
+	for i, guess := range filteredGuesses {
+		scores[i] = coverageScore(letterFreqs, guess)
+	}
+
+	best1, best2, bestScore := -1, -1, -1
+	for i := range len(filteredGuesses) - 1 {
+		for j := i + 1; j < len(filteredGuesses); j++ {
+			if guessBitvecs[i].Intersects(guessBitvecs[j]) {
+				continue
+			}
+			if scores[i]+scores[j] > bestScore {
+				best1, best2, bestScore = i, j, scores[i]+scores[j]
+			}
+		}
+	}
+
+	if best1 == -1 {
+		return "", ""
+	}
+
+	return filteredGuesses[best1], filteredGuesses[best2]
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLetterFrequencies(t *testing.T) {
+	useFixture(t)
+
+	for i, freqs := range PositionalFrequencies() {
+		sum := 0
+		for _, n := range freqs {
+			sum += n
+		}
+		if sum != len(answers) {
+			t.Errorf("position %d counts add up to %d, want %d", i, sum, len(answers))
+		}
+	}
+
+	wantSum := 0
+	for _, answer := range answers {
+		distinct := make(map[byte]bool)
+		for i := range len(answer) {
+			distinct[answer[i]] = true
+		}
+		wantSum += len(distinct)
+	}
+
+	letterFreqs := LetterFrequencies()
+	sum := 0
+	for _, n := range letterFreqs {
+		sum += n
+	}
+	if sum != wantSum {
+		t.Errorf("letter counts add up to %d, want %d", sum, wantSum)
+	}
+
+	// t is in 15 of the 20 fixture answers
+	if top := Alphabet[slices.Index(letterFreqs, slices.Max(letterFreqs))]; top != 't' {
+		t.Errorf("most common letter is %c, want t", top)
+	}
+}
+
+func TestScoreByLetterFrequency(t *testing.T) {
+	useFixture(t)
+
+	tables := NewLetterTables()
+	for _, guess := range guesses {
+		if got, want := ScoreByLetterFrequency(guess), tables.Score(guess); got != want {
+			t.Errorf("ScoreByLetterFrequency(%q) = %d, LetterTables.Score gave %d", guess, got, want)
+		}
+	}
+
+	if slate, fmnst := ScoreByLetterFrequency("slate"), ScoreByLetterFrequency("fmnst"); slate <= fmnst {
+		t.Errorf("slate scored %d, not above fmnst's %d", slate, fmnst)
+	}
+
+	// anything that isn't a letter, or is past WordLen, is skipped
+	tests := []struct {
+		guess string
+		want  int
+	}{
+		{"", 0},
+		{"!!!!!", 0},
+		{"CRANE", 0},
+		{"t!!!!", tables.Positional[0][letterIndex('t')] + tables.Letters[letterIndex('t')]},
+		{"cranes", ScoreByLetterFrequency("crane")},
+	}
+	for _, tt := range tests {
+		if got := ScoreByLetterFrequency(tt.guess); got != tt.want {
+			t.Errorf("ScoreByLetterFrequency(%q) = %d, want %d", tt.guess, got, tt.want)
+		}
+	}
+}
+
+func TestCoverageScore(t *testing.T) {
+	useFixture(t)
+
+	disjoint := CoverageScore("crane", "sight")
+	overlapping := CoverageScore("crane", "crate")
+	if disjoint <= overlapping {
+		t.Errorf("crane, sight scored %d, not above crane, crate's %d", disjoint, overlapping)
+	}
+	if repeated := CoverageScore("crane", "crane"); repeated != CoverageScore("crane") {
+		t.Errorf("repeating a word changed its score from %d to %d", CoverageScore("crane"), repeated)
+	}
+	if got, want := CoverageScore("cr4ne!"), CoverageScore("crne"); got != want {
+		t.Errorf("CoverageScore(\"cr4ne!\") = %d, want %d with only its letters counted", got, want)
+	}
+
+	word1, word2 := BestCoveragePair()
+	best := CoverageScore(word1, word2)
+	filtered, letters := uniqueLetterGuesses()
+	for i := range filtered {
+		for j := i + 1; j < len(filtered); j++ {
+			if !letters[i].Intersects(letters[j]) && CoverageScore(filtered[i], filtered[j]) > best {
+				t.Errorf("%v, %v beats BestCoveragePair's %v, %v", filtered[i], filtered[j], word1, word2)
+			}
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sync"
+)
+
+// GuessResult is a guess that was played and the hint it got back
+type GuessResult struct {
+	Guess string
+	Hint  Hint
+}
+
+// Game tracks one board: the guesses played so far and the answers still
+// consistent with them
+type Game struct {
+	candidates *Bitvec
+	history    []GuessResult
+}
+
+// NewGame starts a game where every answer is still a candidate
+func NewGame() *Game {
+	return &Game{candidates: allAnswers()}
+}
+
+// NewGameWithHints starts a game with prefill already applied, for puzzles
+// that reveal some letters up front or to resume a game part way through.
+// It fails if a row's guess is the wrong length, rather than leave out a
+// constraint the caller asked for.
+func NewGameWithHints(prefill []GuessResult) (*Game, error) {
+	g := NewGame()
+	for i, result := range prefill {
+		if err := g.Apply(result.Guess, result.Hint); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+
+	return g, nil
+}
+
+// Apply narrows the candidates down to the answers that would have given hint
+// for guess. A guess of the wrong length is an error and changes nothing.
+func (g *Game) Apply(guess string, hint Hint) error {
+	if err := checkWord(guess); err != nil {
+		return err
+	}
+
+	g.history = append(g.history, GuessResult{guess, hint})
+	g.candidates = g.narrowed(guess, hint)
+	return nil
+}
+
+// narrowed returns the candidates that would be left after guess got hint,
+// without changing g
+func (g *Game) narrowed(guess string, hint Hint) *Bitvec {
+	// prefilled rows don't have to be real guesses, so there may be no
+	// precomputed bitvecs for them
+	if lookupGuessInfo(guess) == nil {
+		filtered := NewBitvec(len(answers))
+		for i := g.candidates.FirstSet(); i != -1; i = g.candidates.NextSet(i) {
+			if getHint(guess, answers[i]) == hint {
+				filtered.Set(i)
+			}
+		}
+		return filtered
+	}
+
+	bitvec := lookupGuessInfo(guess).hintBitvec(hint)
+	if bitvec == nil {
+		// no answer gives this hint, so nothing is left
+		return NewBitvec(len(answers))
+	}
+	return g.candidates.And(bitvec)
+}
+
+// Solved reports whether the last guess was all green
+func (g *Game) Solved() bool {
+	return len(g.history) > 0 && g.history[len(g.history)-1].Hint == allGreen()
+}
+
+// Remaining returns the answers that are still candidates
+func (g *Game) Remaining() []string {
+	var words []string
+	for i := g.candidates.FirstSet(); i != -1; i = g.candidates.NextSet(i) {
+		words = append(words, answers[i])
+	}
+
+	return words
+}
+
+// RemainingCount is len(Remaining()) without building the slice
+func (g *Game) RemainingCount() int {
+	return g.candidates.Count
+}
+
+// NextGuess picks the next guess the same way SolveVerbose does, except it
+// never repeats a word that's already been played. It fails if no candidates
+// are left, or the only ones left were already played, which means a hint
+// was entered wrong.
+func (g *Game) NextGuess() (string, error) {
+	if g.candidates.Count == 0 {
+		return "", errors.New("no candidates left, the hints contradict each other")
+	}
+
+	// a played word gets the same hint for every remaining candidate, so it's
+	// never the best guess while there are several candidates to split. The
+	// only way to pick one is guessing a candidate directly.
+	if g.candidates.Count <= 2 {
+		unplayed := false
+		for i := g.candidates.FirstSet(); i != -1; i = g.candidates.NextSet(i) {
+			if g.played(answers[i]) {
+				continue
+			}
+			unplayed = true
+			if hasLetters(answers[i], RequiredLetters) {
+				return answers[i], nil
+			}
+		}
+		if !unplayed {
+			return "", errors.New("every remaining candidate was already guessed, a hint must be wrong")
+		}
+	}
+
+	// with 2 or fewer candidates left this only gets here when none of them
+	// has RequiredLetters, so chooseGuess looks for a guess that splits them
+	guess, _ := chooseGuess(g.candidates)
+	if guess == "" || g.played(guess) {
+		return "", errors.New("no allowed guess has every required letter")
+	}
+	return guess, nil
+}
+
+// played reports whether word has already been guessed in this game
+func (g *Game) played(word string) bool {
+	for _, result := range g.history {
+		if result.Guess == word {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Solution returns the answer once it's the only candidate left. It returns
+// false if there are several candidates, or none because the hints contradict.
+func (g *Game) Solution() (string, bool) {
+	if g.candidates.Count != 1 {
+		return "", false
+	}
+
+	return answers[g.candidates.FirstSet()], true
+}
+
+// GuessQuality says how much guess would narrow down the current candidates:
+// the expected number left, the most that could be left, and the expected
+// information in bits. A word that was already played, or isn't in the guess
+// list, scores as narrowing nothing: every candidate left and 0 bits.
+func (g *Game) GuessQuality(guess string) (expectedRemaining float64, worstCase int, entropyBits float64) {
+	if g.played(guess) || lookupGuessInfo(guess) == nil {
+		return float64(g.candidates.Count), g.candidates.Count, 0
+	}
+
+	return ExpectedRemaining(guess, g.candidates),
+		WorstCaseBucket(guess, g.candidates),
+		Entropy(guess, g.candidates)
+}
+
+// InformationGained is how many bits guess getting hint would tell us, i.e.
+// log2 of how many times fewer candidates there would be. Getting down to one
+// candidate gains all the bits that were left. It doesn't change g, and a
+// hint that leaves nothing gains 0 since it must be a mistake, as does a
+// guess of the wrong length.
+func InformationGained(g *Game, guess string, h Hint) float64 {
+	if checkWord(guess) != nil {
+		return 0
+	}
+
+	before := g.candidates.Count
+	after := g.narrowed(guess, h).Count
+	if before == 0 || after == 0 {
+		return 0
+	}
+
+	return math.Log2(float64(before) / float64(after))
+}
+
+// Save writes the game's history as a JSON transcript. The candidates aren't
+// saved since LoadGame recomputes them.
+func (g *Game) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(g.history)
+}
+
+// LoadGame replays a transcript written by Save. It fails if a hint can't be
+// parsed, a guess is the wrong length, or the history leaves no candidates, so
+// a corrupt save never loads as some other game.
+func LoadGame(r io.Reader) (*Game, error) {
+	history, err := LoadTranscript(r)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := NewGameWithHints(history)
+	if err != nil {
+		return nil, fmt.Errorf("loading game: %w", err)
+	}
+	if g.candidates.Count == 0 {
+		return nil, errors.New("loading game: no candidates are consistent with the history")
+	}
+
+	return g, nil
+}
+
+// WhyEliminated explains why word is no longer a candidate, using the first
+// row of the history it contradicts, e.g. "guess 'crane' marked 'c' gray but
+// 'cloud' contains 'c'". Returns "" if word is still a candidate.
+func (g *Game) WhyEliminated(word string) string {
+	if !slices.Contains(answers, word) {
+		return fmt.Sprintf("'%s' isn't in the answer list", word)
+	}
+
+	for _, row := range g.history {
+		if len(word) != len(row.Guess) {
+			continue
+		}
+
+		want := row.Hint.Digits()
+		got := getHint(row.Guess, word).Digits()
+
+		for i := range want {
+			if want[i] == got[i] {
+				continue
+			}
+
+			ch := row.Guess[i]
+			switch {
+			case want[i] == '0':
+				return fmt.Sprintf("guess '%s' marked '%c' gray but '%s' contains '%c'", row.Guess, ch, word, ch)
+			case want[i] == '1' && got[i] == '0':
+				return fmt.Sprintf("guess '%s' marked '%c' yellow but '%s' doesn't contain '%c'", row.Guess, ch, word, ch)
+			case want[i] == '1':
+				return fmt.Sprintf("guess '%s' marked '%c' yellow but '%s' has '%c' in that spot", row.Guess, ch, word, ch)
+			case want[i] == '2':
+				return fmt.Sprintf("guess '%s' marked '%c' green but '%s' has '%c' there", row.Guess, ch, word, word[i])
+			default:
+				return fmt.Sprintf("guess '%s' got a tile state for '%c' that no answer gives", row.Guess, ch)
+			}
+		}
+	}
+
+	return ""
+}
+
+// KeyboardState is what Wordle's keyboard shows: for every letter that's been
+// guessed, the best hint digit it got in any row, 0 for gray, 1 for yellow,
+// and 2 for green. Letters that haven't been guessed are left out.
+func (g *Game) KeyboardState() map[byte]uint8 {
+	state := make(map[byte]uint8)
+	for _, row := range g.history {
+		for i, digit := range row.Hint.Digits() {
+			ch, d := row.Guess[i], uint8(digit-'0')
+			// the extra tile state HintBase 4 adds still means the letter is
+			// there, so show it like yellow
+			if d > 2 {
+				d = 1
+			}
+			if cur, ok := state[ch]; !ok || d > cur {
+				state[ch] = d
+			}
+		}
+	}
+
+	return state
+}
+
+// untestedLetterWeight is how much BestInformativeGuess discounts a guess's
+// ExpectedRemaining for each letter it'd test that isn't on KeyboardState
+// yet: 0.02 takes 2% off per letter, so at most 10% for WordLen new letters.
+// It's small so it only decides between guesses that split the candidates
+// about as well as each other.
+const untestedLetterWeight = 0.02
+
+// BestInformativeGuess is like NextGuess, but weights each guess's
+// ExpectedRemaining by how many letters it'd test that aren't on
+// KeyboardState yet, so between similar guesses it picks the one that learns
+// about more of the alphabet. Ties go to list order. With 2 or fewer
+// candidates left it guesses one like NextGuess, and it returns "" if there's
+// nothing to guess.
+func (g *Game) BestInformativeGuess() string {
+	if g.candidates.Count <= 2 {
+		guess, _ := g.NextGuess()
+		return guess
+	}
+
+	allowed := allowedGuesses()
+	keyboard := g.KeyboardState()
+	scores := make([]float64, len(allowed))
+
+	wg := sync.WaitGroup{}
+
+	for i, guess := range allowed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			untested := make(map[byte]bool)
+			for j := range len(guess) {
+				if _, ok := keyboard[guess[j]]; !ok {
+					untested[guess[j]] = true
+				}
+			}
+			weight := 1 - untestedLetterWeight*float64(len(untested))
+			scores[i] = ExpectedRemaining(guess, g.candidates) * weight
+		}()
+	}
+
+	wg.Wait()
+
+	bestIdx := -1
+	for i := range allowed {
+		if bestIdx == -1 || scores[i] < scores[bestIdx] {
+			bestIdx = i
+		}
+	}
+	if bestIdx == -1 {
+		return ""
+	}
+
+	return allowed[bestIdx]
+}
+
+// GreenProbabilities is, for each position and letter of Alphabet, indexed by
+// letterIndex, the fraction of the remaining candidates with that letter in
+// that position, i.e. how likely it is to be green there. Each position sums
+// to 1 unless no candidates are left, in which case it's all 0.
+func (g *Game) GreenProbabilities() [WordLen][]float64 {
+	var probs [WordLen][]float64
+	for i := range probs {
+		probs[i] = make([]float64, len(Alphabet))
+	}
+	if g.candidates.Count == 0 {
+		return probs
+	}
+
+	for idx := g.candidates.FirstSet(); idx != -1; idx = g.candidates.NextSet(idx) {
+		for i := range WordLen {
+			if j := letterIndex(answers[idx][i]); j != -1 {
+				probs[i][j]++
+			}
+		}
+	}
+
+	for i := range probs {
+		for j := range probs[i] {
+			probs[i][j] /= float64(g.candidates.Count)
+		}
+	}
+
+	return probs
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGameSolution(t *testing.T) {
+	useFixture(t)
+
+	g := NewGame()
+	if word, ok := g.Solution(); ok {
+		t.Errorf("Solution() with every answer left = %q, true", word)
+	}
+
+	g.Apply("fmnst", getHint("fmnst", "tonal"))
+	if word, ok := g.Solution(); !ok || word != "tonal" {
+		t.Errorf("Solution() with only tonal left = %q, %v", word, ok)
+	}
+
+	// a hint no answer gives leaves nothing
+	g.Apply("slate", allGreen())
+	if word, ok := g.Solution(); ok {
+		t.Errorf("Solution() with no candidates = %q, true", word)
+	}
+}
+
+func TestNewGameWithHints(t *testing.T) {
+	useFixture(t)
+
+	// s gray and ight green
+	g, err := NewGameWithHints([]GuessResult{{"sight", getHint("sight", "light")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"fight", "light", "might", "night", "right", "tight", "wight"}
+	if got := g.Remaining(); !slices.Equal(got, want) {
+		t.Errorf("Remaining() = %v, want %v", got, want)
+	}
+
+	// a row of the wrong length is an error, not a constraint to drop
+	g, err = NewGameWithHints([]GuessResult{{"sight", 0}, {"cat", 0}})
+	if err == nil || g != nil || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("NewGameWithHints with a bad second row = %v, %v", g, err)
+	}
+}
+
+func TestGuessQuality(t *testing.T) {
+	useFixture(t)
+
+	g := NewGame()
+	g.Apply("fmnst", getHint("fmnst", "crate"))
+	if g.RemainingCount() != 4 {
+		t.Fatalf("%d candidates left, want 4: %v", g.RemainingCount(), g.Remaining())
+	}
+
+	// tight gives each of the 4 a different hint
+	expected, worst, bits := g.GuessQuality("tight")
+	if expected != 1 || worst != 1 || math.Abs(bits-2) > 1e-9 {
+		t.Errorf("GuessQuality(tight) = %v, %v, %v, want 1, 1, 2", expected, worst, bits)
+	}
+
+	for _, guess := range []string{"fmnst", "zzzzz", "cat", ""} {
+		expected, worst, bits := g.GuessQuality(guess)
+		if expected != 4 || worst != 4 || bits != 0 {
+			t.Errorf("GuessQuality(%q) = %v, %v, %v, want 4, 4, 0", guess, expected, worst, bits)
+		}
+	}
+
+	// tight would still split the candidates, but it's been played already,
+	// so it mustn't look worth playing again
+	played := &Game{candidates: g.candidates, history: []GuessResult{{"tight", getHint("tight", "crate")}}}
+	if expected, worst, bits := played.GuessQuality("tight"); expected != 4 || worst != 4 || bits != 0 {
+		t.Errorf("GuessQuality(tight) after playing it = %v, %v, %v, want 4, 4, 0", expected, worst, bits)
+	}
+
+	// the package level scores have no buckets to go on, but mustn't panic
+	if ExpectedRemaining("zzzzz", g.candidates) != 0 || WorstCaseBucket("zzzzz", g.candidates) != 0 ||
+		Entropy("zzzzz", g.candidates) != 0 {
+		t.Error("an unknown guess has buckets")
+	}
+	if hint, left := AdversarialHint("zzzzz", g.candidates); hint != 0 || left != g.candidates {
+		t.Errorf("AdversarialHint(zzzzz) = %v, %v, want 0 and the candidates unchanged", hint, left)
+	}
+}
+
+func TestNextGuessNeverRepeats(t *testing.T) {
+	useFixture(t)
+
+	for _, answer := range answers {
+		g := NewGame()
+		for !g.Solved() {
+			guess, err := g.NextGuess()
+			if err != nil {
+				t.Fatalf("%v: %v", answer, err)
+			}
+			if g.played(guess) {
+				t.Fatalf("%v: recommended %v again after %v", answer, guess, g.history)
+			}
+			g.Apply(guess, getHint(guess, answer))
+		}
+	}
+}
+
+func TestNextGuessAfterMistypedHint(t *testing.T) {
+	useFixture(t)
+
+	// if light's hint is entered as all green by mistake and play carries on,
+	// light is the only candidate left even though it's been played
+	g := NewGame()
+	g.Apply("light", allGreen())
+	g.Apply("fmnst", getHint("fmnst", "light"))
+	if got := g.Remaining(); !slices.Equal(got, []string{"light"}) {
+		t.Fatalf("Remaining() = %v, want [light]", got)
+	}
+
+	if guess, err := g.NextGuess(); err == nil {
+		t.Errorf("NextGuess() = %v, want an error", guess)
+	}
+}
+
+func TestInformationGained(t *testing.T) {
+	useFixture(t)
+
+	g := NewGame()
+	g.Apply("fmnst", getHint("fmnst", "crate"))
+	if g.RemainingCount() != 4 {
+		t.Fatalf("%d candidates left, want 4: %v", g.RemainingCount(), g.Remaining())
+	}
+
+	tests := []struct {
+		guess, answer string
+		want          float64
+	}{
+		{"fight", "crate", 1}, // leaves crate and taker
+		{"fight", "irate", 2}, // only irate has an i, so that's everything
+		{"fmnst", "crate", 0}, // nothing new
+	}
+	for _, tt := range tests {
+		if got := InformationGained(g, tt.guess, getHint(tt.guess, tt.answer)); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("InformationGained(%s against %s) = %v, want %v", tt.guess, tt.answer, got, tt.want)
+		}
+	}
+
+	// a hint none of the candidates would give
+	if got := InformationGained(g, "fight", allGreen()); got != 0 {
+		t.Errorf("InformationGained for an impossible hint = %v, want 0", got)
+	}
+	for _, guess := range []string{"cat", "cranes"} {
+		if got := InformationGained(g, guess, 0); got != 0 {
+			t.Errorf("InformationGained(%q) = %v, want 0", guess, got)
+		}
+	}
+	if g.RemainingCount() != 4 {
+		t.Errorf("InformationGained changed the game")
+	}
+}
+
+func TestSaveLoadGame(t *testing.T) {
+	useFixture(t)
+
+	g := NewGame()
+	g.Apply("fmnst", getHint("fmnst", "crate"))
+	g.Apply("baker", getHint("baker", "crate"))
+	want, err := g.NextGuess()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var saved bytes.Buffer
+	if err := g.Save(&saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadGame(&saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(loaded.history, g.history) || !slices.Equal(loaded.Remaining(), g.Remaining()) {
+		t.Errorf("loaded %v with %v left, want %v with %v left",
+			loaded.history, loaded.Remaining(), g.history, g.Remaining())
+	}
+	if got, err := loaded.NextGuess(); got != want || err != nil {
+		t.Errorf("loaded game's NextGuess() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestLoadGameErrors(t *testing.T) {
+	useFixture(t)
+
+	bad := []string{
+		`[{"guess":"fmnst","hint":"0000x"}]`, // unparseable hint
+		`[{"guess":"fmnst","hint":"22222"}]`, // fmnst isn't an answer
+		`not json`,
+		// a row from a game with another word length
+		`[{"guess":"fmnst","hint":"00001"},{"guess":"cat","hint":"00000"}]`,
+	}
+	for _, transcript := range bad {
+		if g, err := LoadGame(strings.NewReader(transcript)); err == nil {
+			t.Errorf("LoadGame(%s) loaded a game with %v left", transcript, g.Remaining())
+		}
+	}
+}
+
+func TestRemainingCount(t *testing.T) {
+	useFixture(t)
+
+	g := NewGame()
+	if g.RemainingCount() != len(g.Remaining()) || g.RemainingCount() != len(answers) {
+		t.Fatalf("new game: RemainingCount() = %d, len(Remaining()) = %d", g.RemainingCount(), len(g.Remaining()))
+	}
+
+	for _, guess := range []string{"fmnst", "baker", "tight", "crate"} {
+		g.Apply(guess, getHint(guess, "crate"))
+		if g.RemainingCount() != len(g.Remaining()) {
+			t.Errorf("after %v: RemainingCount() = %d, len(Remaining()) = %d", guess, g.RemainingCount(), len(g.Remaining()))
+		}
+	}
+	if g.RemainingCount() != 1 {
+		t.Errorf("%d left after guessing crate, want 1", g.RemainingCount())
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { g.RemainingCount() }); allocs != 0 {
+		t.Errorf("RemainingCount made %v allocations", allocs)
+	}
+}
+
+func TestWhyEliminated(t *testing.T) {
+	useFixture(t)
+
+	g := NewGame()
+	for _, guess := range []string{"fmnst", "taker", "crane"} {
+		g.Apply(guess, getHint(guess, "crate"))
+	}
+
+	tests := []struct {
+		word, want string
+	}{
+		{"tonal", "guess 'fmnst' marked 'n' gray but 'tonal' contains 'n'"},
+		{"baker", "guess 'fmnst' marked 't' yellow but 'baker' doesn't contain 't'"},
+		{"light", "guess 'fmnst' marked 't' yellow but 'light' has 't' in that spot"},
+		{"grate", "guess 'crane' marked 'c' green but 'grate' has 'g' there"},
+		{"crate", ""},
+		{"fmnst", "'fmnst' isn't in the answer list"},
+	}
+	for _, tt := range tests {
+		if got := g.WhyEliminated(tt.word); got != tt.want {
+			t.Errorf("WhyEliminated(%s) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestBestInformativeGuess(t *testing.T) {
+	useFixture(t)
+
+	g := NewGame()
+	g.Apply("crane", getHint("crane", "baker"))
+	if got := g.Remaining(); !slices.Equal(got, []string{"baker", "maker", "taker"}) {
+		t.Fatalf("Remaining() = %v", got)
+	}
+
+	// both tell the three apart, but crane already tested fmnst's n
+	if ExpectedRemaining("fmnst", g.candidates) != ExpectedRemaining("might", g.candidates) {
+		t.Fatalf("fmnst and might leave %v and %v, want them equal",
+			ExpectedRemaining("fmnst", g.candidates), ExpectedRemaining("might", g.candidates))
+	}
+	if guess, _ := g.NextGuess(); guess != "fmnst" {
+		t.Fatalf("NextGuess() = %v, want fmnst", guess)
+	}
+	if got := g.BestInformativeGuess(); got != "might" {
+		t.Errorf("BestInformativeGuess() = %v, want might", got)
+	}
+}
+
+func TestBestInformativeGuessOutweighs(t *testing.T) {
+	// the 27 answers left after tonal against adult
+	useWordLists(t, "testdata/informative_guesses.txt", "testdata/informative_answers.txt")
+
+	g := NewGame()
+	g.Apply("tonal", getHint("tonal", "adult"))
+	if g.RemainingCount() != len(answers) {
+		t.Fatalf("%d candidates left after tonal, want all %d", g.RemainingCount(), len(answers))
+	}
+
+	// least leaves fewer on average, but only its e and s are new, where all
+	// of parse's letters but a are
+	least, parse := ExpectedRemaining("least", g.candidates), ExpectedRemaining("parse", g.candidates)
+	if least >= parse {
+		t.Fatalf("least leaves %v, no fewer than parse's %v", least, parse)
+	}
+	if guess, _ := g.NextGuess(); guess != "least" {
+		t.Fatalf("NextGuess() = %v, want least", guess)
+	}
+	if got := g.BestInformativeGuess(); got != "parse" {
+		t.Errorf("BestInformativeGuess() = %v, want parse", got)
+	}
+}
+
+func TestGreenProbabilities(t *testing.T) {
+	useFixture(t)
+
+	g := NewGame()
+	probs := g.GreenProbabilities()
+	for i, row := range probs {
+		sum := 0.0
+		for _, p := range row {
+			sum += p
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("position %d sums to %v, want 1", i, sum)
+		}
+	}
+	// 8 of the 20 answers end in ight
+	if p := probs[1][letterIndex('i')]; p != 0.4 {
+		t.Errorf("i in position 1 has probability %v, want 0.4", p)
+	}
+
+	g.Apply("fmnst", getHint("fmnst", "tonal"))
+	probs = g.GreenProbabilities()
+	for i, row := range probs {
+		for j, p := range row {
+			want := 0.0
+			if Alphabet[j] == "tonal"[i] {
+				want = 1
+			}
+			if p != want {
+				t.Errorf("only tonal left: %c in position %d has probability %v, want %v", Alphabet[j], i, p, want)
+			}
+		}
+	}
+
+	g.Apply("slate", allGreen())
+	for i, row := range g.GreenProbabilities() {
+		if slices.ContainsFunc(row, func(p float64) bool { return p != 0 }) {
+			t.Errorf("no candidates left: position %d = %v, want all 0", i, row)
+		}
+	}
+}
@@ -2,11 +2,14 @@ module github.com/bent101/go-wordle-solving
 
 go 1.24.4
 
+require (
+	github.com/schollz/progressbar/v3 v3.18.0
+	golang.org/x/exp v0.0.0-20250606033433-dcc06ee1d476
+)
+
 require (
 	github.com/mitchellh/colorstring v0.0.0-20190213212951-d06e56a500db // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	github.com/schollz/progressbar/v3 v3.18.0 // indirect
-	golang.org/x/exp v0.0.0-20250606033433-dcc06ee1d476 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/term v0.32.0 // indirect
 )
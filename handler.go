@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// openerMaxAge is how long clients and CDNs may cache OpenerHandler's
+// response. The opener only changes when the word lists do, which means a
+// new deploy, so it can be cached for a long time.
+const openerMaxAge = 365 * 24 * 60 * 60
+
+// openerResponse is the JSON OpenerHandler writes
+type openerResponse struct {
+	Opener string `json:"opener"`
+}
+
+// pickOpener returns the guess the solver plays first with every answer
+// still a candidate
+func pickOpener() string {
+	opener, _ := chooseGuess(allAnswers())
+	return opener
+}
+
+// memoOpener is pickOpener, run once on the first request after a cold start
+var memoOpener = sync.OnceValue(pickOpener)
+
+// OpenerHandler serves the solver's opener as JSON, e.g. {"opener":"salet"},
+// with a long Cache-Control max-age. It fails with a 500 if there's no opener
+// to give, e.g. because the word lists are empty.
+func OpenerHandler(w http.ResponseWriter, r *http.Request) {
+	opener := memoOpener()
+	if opener == "" {
+		http.Error(w, "no opener, the word lists or guesses cache are empty", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", openerMaxAge))
+	json.NewEncoder(w).Encode(openerResponse{opener})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOpenerHandler(t *testing.T) {
+	useFixture(t)
+	// the memo may have been filled in from other word lists
+	setForTest(t, &memoOpener, sync.OnceValue(pickOpener))
+
+	rec := httptest.NewRecorder()
+	OpenerHandler(rec, httptest.NewRequest(http.MethodGet, "/opener", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if cacheControl := rec.Header().Get("Cache-Control"); !strings.Contains(cacheControl, "max-age=") {
+		t.Errorf("Cache-Control = %q, want a max-age", cacheControl)
+	}
+
+	var resp openerResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("body isn't JSON: %v\n%s", err, rec.Body.String())
+	}
+	if want, _ := chooseGuess(allAnswers()); resp.Opener != want {
+		t.Errorf("opener = %q, want %q", resp.Opener, want)
+	}
+}
+
+func TestOpenerHandlerNoOpener(t *testing.T) {
+	useFixture(t)
+	setForTest(t, &memoOpener, sync.OnceValue(pickOpener))
+	SetWordLists(nil, nil)
+
+	rec := httptest.NewRecorder()
+	OpenerHandler(rec, httptest.NewRequest(http.MethodGet, "/opener", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status %d with an empty cache, want 500", rec.Code)
+	}
+	if cacheControl := rec.Header().Get("Cache-Control"); cacheControl != "" {
+		t.Errorf("an error was sent with Cache-Control %q", cacheControl)
+	}
+}
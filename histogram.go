@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// HintHistogram counts how many answers give each hint for this guess
+func (gi *GuessInfo) HintHistogram() map[Hint]int {
+	histogram := make(map[Hint]int)
+	for _, hint := range gi.AnswerHints {
+		histogram[hint]++
+	}
+
+	return histogram
+}
+
+// WriteHintHistogramCSV writes guess's HintHistogram as CSV, biggest bucket
+// first. Each row has the hint as emoji, the hint as digits, and the
+// number of answers that give it.
+func WriteHintHistogramCSV(guess string, w io.Writer) error {
+	guessInfo := lookupGuessInfo(guess)
+	if guessInfo == nil {
+		return fmt.Errorf("%q is not a known guess", guess)
+	}
+
+	histogram := guessInfo.HintHistogram()
+	hints := make([]Hint, 0, len(histogram))
+	for hint := range histogram {
+		hints = append(hints, hint)
+	}
+
+	// ties go to the smaller hint so the output is stable
+	sort.Slice(hints, func(i, j int) bool {
+		if histogram[hints[i]] != histogram[hints[j]] {
+			return histogram[hints[i]] > histogram[hints[j]]
+		}
+		return hints[i] < hints[j]
+	})
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"hint", "digits", "count"})
+	for _, hint := range hints {
+		writer.Write([]string{hint.String(), hint.Digits(), strconv.Itoa(histogram[hint])})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// CandidateDistribution plays the fixed sequence of guesses against every
+// answer and counts how many answers end up with each number of candidates
+// left, counted the same way as AvgNumCandidates. AvgNumCandidates is the
+// mean of this, but the spread shows how often a sequence leaves a lot. Like
+// GroupByHint, it's empty if any guess in sequence isn't a known guess.
+func CandidateDistribution(sequence ...string) map[int]int {
+	distribution := make(map[int]int)
+	if len(sequence) == 0 {
+		distribution[len(answers)] = len(answers)
+		return distribution
+	}
+	for _, guess := range sequence {
+		if lookupGuessInfo(guess) == nil {
+			return distribution
+		}
+	}
+
+	scratch := getScratchBitvec()
+	defer putScratchBitvec(scratch)
+
+	for _, answer := range answers {
+		distribution[numCandidatesLeft(answer, sequence[0], sequence[1:], scratch)]++
+	}
+
+	return distribution
+}
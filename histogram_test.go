@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHintHistogram(t *testing.T) {
+	useFixture(t)
+
+	for _, guess := range guesses {
+		histogram := lookupGuessInfo(guess).HintHistogram()
+
+		sum := 0
+		for hint, count := range histogram {
+			if count <= 0 {
+				t.Errorf("%s: hint %s has count %d", guess, hint.Digits(), count)
+			}
+			sum += count
+		}
+		if sum != len(answers) {
+			t.Errorf("%s: histogram adds up to %d, want %d", guess, sum, len(answers))
+		}
+	}
+}
+
+func TestWriteHintHistogramCSV(t *testing.T) {
+	useFixture(t)
+
+	var out strings.Builder
+	if err := WriteHintHistogramCSV("fmnst", &out); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	histogram := lookupGuessInfo("fmnst").HintHistogram()
+	if len(rows) != len(histogram)+1 || strings.Join(rows[0], ",") != "hint,digits,count" {
+		t.Fatalf("got %d rows starting with %q for %d hints", len(rows), rows[0], len(histogram))
+	}
+
+	prev := len(answers)
+	for _, row := range rows[1:] {
+		digits, err := hintDigits(row[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		hint, err := hintFromDigits(digits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count, _ := strconv.Atoi(row[2])
+		if row[0] != hint.String() || count != histogram[hint] {
+			t.Errorf("row %q, want %s with count %d", row, hint.String(), histogram[hint])
+		}
+		if count > prev {
+			t.Errorf("row %q comes after a count of %d", row, prev)
+		}
+		prev = count
+	}
+
+	if err := WriteHintHistogramCSV("zzzzz", &out); err == nil {
+		t.Error("no error for an unknown guess")
+	}
+}
+
+func TestCandidateDistribution(t *testing.T) {
+	useFixture(t)
+
+	for _, sequence := range [][]string{{"fmnst"}, {"fmnst", "baker"}, {"crane", "tight", "steal"}, {"roate", "fight"}} {
+		distribution := CandidateDistribution(sequence...)
+
+		answersSeen, total := 0, 0
+		for left, n := range distribution {
+			answersSeen += n
+			total += left * n
+		}
+		if answersSeen != len(answers) {
+			t.Errorf("%v: distribution covers %d answers, want %d", sequence, answersSeen, len(answers))
+		}
+		if got, want := float64(total)/float64(answersSeen), AvgNumCandidates(sequence[0], sequence[1:]...); got != want {
+			t.Errorf("%v: distribution's mean is %v, AvgNumCandidates is %v", sequence, got, want)
+		}
+	}
+
+	if got := CandidateDistribution(); len(got) != 1 || got[len(answers)] != len(answers) {
+		t.Errorf("no guesses gave %v", got)
+	}
+	for _, sequence := range [][]string{{"zzzzz"}, {"fmnst", "zzzzz"}, {"cat"}} {
+		if got := CandidateDistribution(sequence...); len(got) != 0 {
+			t.Errorf("%v: distribution is %v, want empty for an unknown guess", sequence, got)
+		}
+	}
+}
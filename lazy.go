@@ -0,0 +1,37 @@
+package main
+
+// LazyBitvecs makes calculateHints leave every HintInfo.Bitvec unset so
+// calculateBitvecs can be skipped. Each bitvec is then built the first time
+// something looks it up, which is much faster to start for interactive use.
+var LazyBitvecs = false
+
+// EnsureBitvec fills in hi.Bitvec with the answers that give hint for gi's
+// guess if it hasn't been built yet. It's safe to call concurrently, and the
+// bitvec is only built once.
+func (hi *HintInfo) EnsureBitvec(gi *GuessInfo, hint Hint) {
+	hi.once.Do(func() {
+		if hi.Bitvec != nil {
+			return
+		}
+
+		bitvec := NewBitvec(len(answers))
+		for answerIdx, answer := range answers {
+			if gi.AnswerHints[answer] == hint {
+				bitvec.Set(answerIdx)
+			}
+		}
+		hi.Bitvec = bitvec
+	})
+}
+
+// hintBitvec returns the answers that give hint for gi's guess, building them
+// first if needed. Returns nil if no answer gives hint.
+func (gi *GuessInfo) hintBitvec(hint Hint) *Bitvec {
+	hintInfo := gi.HintsMap[hint]
+	if hintInfo == nil {
+		return nil
+	}
+
+	hintInfo.EnsureBitvec(gi, hint)
+	return hintInfo.Bitvec
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestEnsureBitvecConcurrent(t *testing.T) {
+	setForTest(t, &LazyBitvecs, true)
+	setForTest(t, &ProgressFunc, func(done, total int) {})
+	if err := LoadWordLists("testdata/guesses.txt", "testdata/answers.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := calculateHints(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	guessInfo := lookupGuessInfo("slate")
+	hint := guessInfo.AnswerHints["stale"]
+	if guessInfo.HintsMap[hint].Bitvec != nil {
+		t.Fatal("bitvec was built up front")
+	}
+
+	const callers = 50
+	got := make([]*Bitvec, callers)
+	wg := sync.WaitGroup{}
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i] = guessInfo.hintBitvec(hint)
+		}()
+	}
+	wg.Wait()
+
+	for i := range got {
+		if got[i] != got[0] {
+			t.Fatal("callers got different bitvecs, so it was built more than once")
+		}
+	}
+
+	for i, answer := range answers {
+		if got[0].Get(i) != (getHint("slate", answer) == hint) {
+			t.Errorf("%v: set %v, but its hint is %v", answer, got[0].Get(i), getHint("slate", answer))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+// LetterInfo is what one hint says about one letter of the answer
+type LetterInfo struct {
+	// MustBeAt are the positions the letter is green in
+	MustBeAt []int
+	// CantBeAt are the positions the letter is yellow or gray in
+	CantBeAt []int
+	// Count is how many times the answer has the letter at least, or exactly
+	// if Exact is set. A gray copy of the letter means the answer has no more
+	// copies than the green and yellow ones, so a letter that's only gray has
+	// an exact count of 0.
+	Count int
+	Exact bool
+}
+
+// ToLetterInfos works out what h says about each letter of guess, with
+// repeated letters combined into one LetterInfo. getHint marks every copy of
+// a letter yellow if the answer has it anywhere, so a yellow only says the
+// letter is there at least once, and gray means it isn't there at all. Hints
+// from Wordle itself can also have a gray copy next to a green or yellow one,
+// which means the answer has exactly as many as the green and yellow copies.
+func (h Hint) ToLetterInfos(guess string) map[byte]LetterInfo {
+	infos := make(map[byte]LetterInfo)
+	if len(guess) != WordLen {
+		return infos
+	}
+
+	greens := make(map[byte]int)
+	yellows := make(map[byte]int)
+	for i, d := range h.Sequence() {
+		ch := guess[i]
+		info := infos[ch]
+
+		switch d {
+		case 2:
+			info.MustBeAt = append(info.MustBeAt, i)
+			greens[ch]++
+		case 1:
+			info.CantBeAt = append(info.CantBeAt, i)
+			yellows[ch]++
+		default:
+			info.CantBeAt = append(info.CantBeAt, i)
+			info.Exact = true
+		}
+
+		infos[ch] = info
+	}
+
+	for ch, info := range infos {
+		if info.Exact {
+			info.Count = greens[ch] + yellows[ch]
+		} else {
+			info.Count = greens[ch]
+			if yellows[ch] > 0 {
+				info.Count = max(info.Count, 1)
+			}
+		}
+		infos[ch] = info
+	}
+
+	return infos
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToLetterInfos(t *testing.T) {
+	useFixture(t)
+
+	tests := []struct {
+		guess string
+		hint  Hint
+		want  map[byte]LetterInfo
+	}{
+		{"crane", getHint("crane", "crate"), map[byte]LetterInfo{
+			'c': {MustBeAt: []int{0}, Count: 1},
+			'r': {MustBeAt: []int{1}, Count: 1},
+			'a': {MustBeAt: []int{2}, Count: 1},
+			'n': {CantBeAt: []int{3}, Count: 0, Exact: true},
+			'e': {MustBeAt: []int{4}, Count: 1},
+		}},
+		// getHint marks the second t yellow since taker has a t somewhere
+		{"tight", getHint("tight", "taker"), map[byte]LetterInfo{
+			't': {MustBeAt: []int{0}, CantBeAt: []int{4}, Count: 1},
+			'i': {CantBeAt: []int{1}, Exact: true},
+			'g': {CantBeAt: []int{2}, Exact: true},
+			'h': {CantBeAt: []int{3}, Exact: true},
+		}},
+		// Wordle itself marks the extra t gray, so taker has exactly one
+		{"tight", HintFromSequence([WordLen]int{2, 0, 0, 0, 0}), map[byte]LetterInfo{
+			't': {MustBeAt: []int{0}, CantBeAt: []int{4}, Count: 1, Exact: true},
+			'i': {CantBeAt: []int{1}, Exact: true},
+			'g': {CantBeAt: []int{2}, Exact: true},
+			'h': {CantBeAt: []int{3}, Exact: true},
+		}},
+		// two yellows only say there's at least one
+		{"tight", HintFromSequence([WordLen]int{1, 0, 0, 0, 1}), map[byte]LetterInfo{
+			't': {CantBeAt: []int{0, 4}, Count: 1},
+			'i': {CantBeAt: []int{1}, Exact: true},
+			'g': {CantBeAt: []int{2}, Exact: true},
+			'h': {CantBeAt: []int{3}, Exact: true},
+		}},
+		{"cat", 0, map[byte]LetterInfo{}},
+	}
+	for _, tt := range tests {
+		if got := tt.hint.ToLetterInfos(tt.guess); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v.ToLetterInfos(%v) = %v, want %v", tt.hint.Digits(), tt.guess, got, tt.want)
+		}
+	}
+}
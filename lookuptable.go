@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// lookupTableMagic starts every file ExportLookupTable writes
+const lookupTableMagic = "WLT2"
+
+// LookupTable is the solver's whole strategy precomputed from one opener, so
+// a small client can play without the word lists or the cache
+type LookupTable struct {
+	root *lookupNode
+}
+
+// lookupNode is the guess to play at one point in the game, and the node to
+// go to for each hint it can get back
+type lookupNode struct {
+	guess    string
+	children map[Hint]*lookupNode
+}
+
+// ExportLookupTable plays out every answer from opener the same way
+// SolveVerbose does, up to MaxGuesses, and writes the resulting guess tree in
+// a compact binary form for LoadLookupTable.
+//
+// The format is lookupTableMagic followed by the tree in preorder. Each node
+// is its guess as WordLen bytes, then its number of children, then for each
+// child, in hint order, the hint and the child node. The number of children
+// and the hints are 2 bytes, little endian.
+func ExportLookupTable(opener string, w io.Writer) error {
+	if lookupGuessInfo(opener) == nil {
+		return fmt.Errorf("%q is not a known guess", opener)
+	}
+
+	root := buildLookupNode(allAnswers(), opener, 1)
+
+	writer := bufio.NewWriter(w)
+	writer.WriteString(lookupTableMagic)
+	writeLookupNode(writer, root)
+
+	return writer.Flush()
+}
+
+// buildLookupNode builds the tree under guess being played as guess number
+// depth against candidates
+func buildLookupNode(candidates *Bitvec, guess string, depth int) *lookupNode {
+	node := &lookupNode{guess: guess, children: make(map[Hint]*lookupNode)}
+	if depth >= MaxGuesses {
+		return node
+	}
+
+	guessInfo := lookupGuessInfo(guess)
+	for hint := range bucketCounts(guess, candidates) {
+		if hint == allGreen() {
+			continue
+		}
+
+		bucket := guessInfo.hintBitvec(hint).And(candidates)
+		next, _ := chooseGuess(bucket)
+		if next == "" {
+			// nothing allowed to play, so the table stops here
+			continue
+		}
+		node.children[hint] = buildLookupNode(bucket, next, depth+1)
+	}
+
+	return node
+}
+
+func writeLookupNode(w *bufio.Writer, node *lookupNode) {
+	w.WriteString(node.guess)
+	w.Write(binary.LittleEndian.AppendUint16(nil, uint16(len(node.children))))
+
+	// sorted so the same tree is always written the same way
+	hints := make([]Hint, 0, len(node.children))
+	for hint := range node.children {
+		hints = append(hints, hint)
+	}
+	slices.Sort(hints)
+
+	for _, hint := range hints {
+		w.Write(binary.LittleEndian.AppendUint16(nil, uint16(hint)))
+		writeLookupNode(w, node.children[hint])
+	}
+}
+
+// LoadLookupTable reads a table written by ExportLookupTable
+func LoadLookupTable(r io.Reader) (*LookupTable, error) {
+	reader := bufio.NewReader(r)
+
+	magic := make([]byte, len(lookupTableMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil {
+		return nil, fmt.Errorf("reading lookup table: %w", err)
+	}
+	if string(magic) != lookupTableMagic {
+		return nil, errors.New("reading lookup table: not a lookup table")
+	}
+
+	root, err := readLookupNode(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading lookup table: %w", err)
+	}
+
+	return &LookupTable{root}, nil
+}
+
+func readLookupNode(r *bufio.Reader) (*lookupNode, error) {
+	guess := make([]byte, WordLen)
+	if _, err := io.ReadFull(r, guess); err != nil {
+		return nil, err
+	}
+	var numChildren uint16
+	if err := binary.Read(r, binary.LittleEndian, &numChildren); err != nil {
+		return nil, err
+	}
+
+	node := &lookupNode{guess: string(guess), children: make(map[Hint]*lookupNode, numChildren)}
+	for range numChildren {
+		var hint uint16
+		if err := binary.Read(r, binary.LittleEndian, &hint); err != nil {
+			return nil, err
+		}
+		child, err := readLookupNode(r)
+		if err != nil {
+			return nil, err
+		}
+		node.children[Hint(hint)] = child
+	}
+
+	return node, nil
+}
+
+// Next returns the guess to play after history, or "" if history strays from
+// the table: a guess the table wouldn't have played, a hint no answer gives,
+// a solved game, or more than MaxGuesses guesses.
+func (t *LookupTable) Next(history []GuessResult) string {
+	node := t.root
+	for _, row := range history {
+		if row.Guess != node.guess {
+			return ""
+		}
+		node = node.children[row.Hint]
+		if node == nil {
+			return ""
+		}
+	}
+
+	return node.guess
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLookupTableMatchesSolver(t *testing.T) {
+	useFixture(t)
+
+	var buf bytes.Buffer
+	if err := ExportLookupTable("fmnst", &buf); err != nil {
+		t.Fatal(err)
+	}
+	table, err := LoadLookupTable(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// play every answer with the table and check each guess is the one the
+	// live solver picks from the same candidates
+	for _, answer := range answers {
+		candidates := allAnswers()
+		var history []GuessResult
+		guess := table.Next(history)
+		if guess != "fmnst" {
+			t.Fatalf("%v: opened with %q, want fmnst", answer, guess)
+		}
+		for len(history) < MaxGuesses {
+			hint := getHint(guess, answer)
+			history = append(history, GuessResult{guess, hint})
+			if hint == allGreen() {
+				break
+			}
+			candidates = candidates.And(lookupBitvec(guess, answer))
+
+			want, _ := chooseGuess(candidates)
+			guess = table.Next(history)
+			if guess != want {
+				t.Fatalf("%v: guess %d from the table is %q, solver picks %q", answer, len(history)+1, guess, want)
+			}
+		}
+		if history[len(history)-1].Hint != allGreen() {
+			t.Errorf("%v: not solved in %d guesses", answer, MaxGuesses)
+		}
+	}
+
+	// a guess the table wouldn't play
+	if got := table.Next([]GuessResult{{"crane", 0}}); got != "" {
+		t.Errorf("Next after crane = %q, want \"\"", got)
+	}
+}
+
+func TestLoadLookupTableErrors(t *testing.T) {
+	useFixture(t)
+
+	if err := ExportLookupTable("zzzzz", &bytes.Buffer{}); err == nil {
+		t.Error("ExportLookupTable(zzzzz) didn't return an error")
+	}
+
+	var buf bytes.Buffer
+	if err := ExportLookupTable("fmnst", &buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	for name, bad := range map[string][]byte{
+		"empty":     nil,
+		"bad magic": append([]byte("XXXX"), data[len(lookupTableMagic):]...),
+		"truncated": data[:len(data)-1],
+	} {
+		if _, err := LoadLookupTable(bytes.NewReader(bad)); err == nil {
+			t.Errorf("%v: LoadLookupTable didn't return an error", name)
+		}
+	}
+}
@@ -1,22 +1,142 @@
 package main
 
 import (
+	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+)
+
+// WordLen is the number of letters in a word, and so the number of digits in
+// a Hint
+const WordLen = 5
+
+// Hint packs one digit per letter in base HintBase, first letter first
+type Hint uint16
+
+// hintBaseInfo is a hint base and the allGreen that goes with it, kept
+// together so no one sees a new base with the old allGreen
+type hintBaseInfo struct {
+	base     int
+	allGreen Hint
+}
+
+// currentHintBase is read for every hint, so SetHintBase swaps it atomically
+// rather than everything taking a lock
+var currentHintBase = func() *atomic.Pointer[hintBaseInfo] {
+	var p atomic.Pointer[hintBaseInfo]
+	p.Store(&hintBaseInfo{3, 242})
+	return &p
+}()
+
+// HintBase is how many states a tile can be in. Wordle has 3: 0 for gray, 1
+// for yellow, and 2 for green. Clones with an extra tile state, like "the
+// letter is there but you have too many", use 4, with 3 for that state.
+// getHint never reports the extra state, so it only comes from parsed hints.
+func HintBase() int {
+	return currentHintBase.Load().base
+}
+
+// maxHintBase is the most tile states String, ColoredWord, and hintDigits know
+// how to show and parse
+const maxHintBase = len(HintPalette{})
+
+// SetHintBase changes HintBase, which changes how every hint is packed. Like
+// SetWordLists it throws away the hints in guessesMap, so calculateHints and
+// calculateBitvecs have to be run again. Call it at startup: hints already
+// handed out, e.g. in a Game, are still packed in the old base.
+func SetHintBase(base int) error {
+	if base < 3 || base > maxHintBase {
+		return fmt.Errorf("hint base %d out of range, want 3 to %d", base, maxHintBase)
+	}
 
-	"github.com/schollz/progressbar/v3"
+	var green Hint
+	for range WordLen {
+		green = green*Hint(base) + 2
+	}
+	currentHintBase.Store(&hintBaseInfo{base, green})
+	setGuessesMap(map[string]*GuessInfo{})
+
+	return nil
+}
+
+// HintMode is which colors getHint reports
+type HintMode int
+
+const (
+	// Standard reports green, yellow, and gray like Wordle
+	Standard HintMode = iota
+	// GreensOnly never reports yellow, for clones that only say whether each
+	// letter is in the right spot
+	GreensOnly
 )
 
-type Hint uint8
+// CurrentHintMode is the HintMode getHint uses
+var CurrentHintMode = Standard
+
+// MaxWorkers caps how many goroutines the hint and bitvec calculations,
+// findBestGuess, RankAllGuesses, and the games and openers the solve reports
+// try run at once, e.g. to leave some CPU free on a shared machine. 0 means
+// runtime.NumCPU().
+var MaxWorkers = 0
+
+// newWorkerLimit returns a semaphore with room for MaxWorkers workers. Send
+// on it before starting one and receive once it's done.
+func newWorkerLimit() chan struct{} {
+	workers := MaxWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	return make(chan struct{}, workers)
+}
+
+// trackWorker is called as each worker newWorkerLimit lets through starts,
+// and the function it returns as the worker finishes. It does nothing, but
+// tests swap it out to count how many workers are running at once.
+var trackWorker = func() func() { return func() {} }
+
+// CaseSensitive makes getHint treat upper and lower case as different
+// letters. Turn it off for word lists with capitalized words, e.g. proper
+// nouns, so "Crane" against "crane" is all green. With it off, letterIndex
+// and ValidateWordList fold case too, so the letter tables count "C" as "c".
+var CaseSensitive = true
+
+// allGreen is the hint for guessing the answer, 22222 in base HintBase
+func allGreen() Hint {
+	return currentHintBase.Load().allGreen
+}
+
+// Alphabet is the letters a word can use, one byte each. Languages with more
+// letters can add them here, as long as the word lists use the same single
+// byte encoding since getHint compares bytes.
+var Alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+// letterIndex is b's position in Alphabet, or -1 if it isn't a letter. ASCII
+// upper case is folded to lower case first unless CaseSensitive is on.
+func letterIndex(b byte) int {
+	if !CaseSensitive && 'A' <= b && b <= 'Z' {
+		b += 'a' - 'A'
+	}
+
+	return strings.IndexByte(Alphabet, b)
+}
 
 type HintInfo struct {
 	Bitvec *Bitvec
+
+	// guards building Bitvec when LazyBitvecs is on
+	once sync.Once
 }
 
 type GuessInfo struct {
@@ -29,96 +149,445 @@ var answersFile, _ = os.ReadFile("io/answers.txt")
 var guesses = strings.Split(string(guessesFile), "\n")
 var answers = strings.Split(string(answersFile), "\n")
 
+// GuessesFromAnswersOnly restricts the solvers to guessing words from the
+// answer list, for strict variants that don't accept the other guesses
+var GuessesFromAnswersOnly = false
+
+// RequiredLetters limits the solvers to guesses containing every one of these
+// letters, for players who want to keep some letters in every guess. Unlike
+// hard mode this doesn't come from the hints. That includes guessing one of
+// the last candidates, and the openers the reports accept. If no guess has
+// them all, the solvers have nothing to pick and return "".
+var RequiredLetters []byte
+
+// allowedGuesses returns the words the solvers are allowed to guess
+func allowedGuesses() []string {
+	s := defaultSolver()
+	return s.allowedGuesses()
+}
+
+// hasLetters reports whether word contains every one of letters
+func hasLetters(word string, letters []byte) bool {
+	for _, ch := range letters {
+		if strings.IndexByte(word, ch) == -1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// guessesCache is what gets written to guesses_cache.gob. NumAnswers records
+// the answer list size the bitvecs were built for, so a cache from an older
+// (shorter) answer list gets rebuilt instead of indexing out of range.
+// HintMode records the mode the hints were calculated in. AnswersHash catches
+// an answer list that's the same size but reordered or edited, since bitvec
+// indices are positions in that list. WordLen catches a cache from a build
+// for a different word length, whose hints have a different number of digits.
+// CaseSensitive records whether getHint folded case, which changes the hints
+// for a list with upper case letters in it.
+type guessesCache struct {
+	WordLen       int
+	NumAnswers    int
+	AnswersHash   uint64
+	HintMode      HintMode
+	HintBase      int
+	CaseSensitive bool
+	GuessesMap    map[string]*GuessInfo
+}
+
 // load guessesMap from disk if possible
 var guessesMap = loadGuessesMap()
 
+// guessesMapMu guards swapping guessesMap out while it's being read. A map
+// that has been published is never written to again, except for lazily built
+// bitvecs which EnsureBitvec handles, so readers only need the lock long
+// enough to grab an entry.
+var guessesMapMu sync.RWMutex
+
+// lookupGuessInfo returns guess's entry in guessesMap, or nil. Safe to call
+// while UpdateCache runs.
+func lookupGuessInfo(guess string) *GuessInfo {
+	guessesMapMu.RLock()
+	defer guessesMapMu.RUnlock()
+	return guessesMap[guess]
+}
+
+// setGuessesMap publishes a fully built map in place of guessesMap
+func setGuessesMap(m map[string]*GuessInfo) {
+	guessesMapMu.Lock()
+	defer guessesMapMu.Unlock()
+	guessesMap = m
+}
+
 func loadGuessesMap() map[string]*GuessInfo {
 	file, err := os.Open("guesses_cache.gob")
 	if err != nil {
-		fmt.Println("Cache file not found, will calculate from scratch")
+		fmt.Fprintln(os.Stderr, "Cache file not found, will calculate from scratch")
 		return map[string]*GuessInfo{}
 	}
 	defer file.Close()
 
 	start := time.Now()
 
-	var guessesMap map[string]*GuessInfo
+	var cache guessesCache
 	decoder := gob.NewDecoder(file)
-	err = decoder.Decode(&guessesMap)
+	err = decoder.Decode(&cache)
 	if err != nil {
-		fmt.Println("Error decoding cache, will recalculate:", err)
+		fmt.Fprintln(os.Stderr, "Error decoding cache, will recalculate:", err)
+		return map[string]*GuessInfo{}
+	}
+
+	if cache.WordLen != WordLen {
+		fmt.Fprintf(os.Stderr, "Cache was built for %d letter words but WordLen is %d, will recalculate\n", cache.WordLen, WordLen)
+		return map[string]*GuessInfo{}
+	}
+
+	if cache.NumAnswers != len(answers) {
+		fmt.Fprintf(os.Stderr, "Cache was built for %d answers but there are %d, will recalculate\n", cache.NumAnswers, len(answers))
+		return map[string]*GuessInfo{}
+	}
+
+	if cache.AnswersHash != answersHash() {
+		fmt.Fprintln(os.Stderr, "Cache was built for a different answer list order, will recalculate")
+		return map[string]*GuessInfo{}
+	}
+
+	if cache.HintBase != HintBase() {
+		fmt.Fprintln(os.Stderr, "Cache was built for a different hint base, will recalculate")
 		return map[string]*GuessInfo{}
 	}
 
-	fmt.Printf("Loaded guesses cache with %d entries in %v\n", len(guessesMap), time.Since(start))
-	return guessesMap
+	if cache.HintMode != CurrentHintMode {
+		fmt.Fprintln(os.Stderr, "Cache was built for a different hint mode, will recalculate")
+		return map[string]*GuessInfo{}
+	}
+
+	if cache.CaseSensitive != CaseSensitive {
+		fmt.Fprintln(os.Stderr, "Cache was built with different case sensitivity, will recalculate")
+		return map[string]*GuessInfo{}
+	}
+
+	if !sampleCountsValid(cache.GuessesMap) {
+		fmt.Fprintln(os.Stderr, "Cache has bitvecs with the wrong counts, will recalculate")
+		return map[string]*GuessInfo{}
+	}
+
+	fmt.Fprintf(os.Stderr, "Loaded guesses cache with %d entries in %v\n", len(cache.GuessesMap), time.Since(start))
+	return cache.GuessesMap
 }
 
-func saveGuessesMap() {
-	file, err := os.Create("guesses_cache.gob")
-	if err != nil {
-		fmt.Println("Error creating cache file:", err)
-		return
+// answersHash fingerprints the answer list in order
+func answersHash() uint64 {
+	hash := fnv.New64a()
+	for _, answer := range answers {
+		hash.Write([]byte(answer))
+		hash.Write([]byte{'\n'})
 	}
-	defer file.Close()
 
+	return hash.Sum64()
+}
+
+// sampleCountsValid spot checks the bitvecs of some of the guesses in m with
+// VerifyCount, to catch a corrupted cache without checking all of it
+func sampleCountsValid(m map[string]*GuessInfo) bool {
+	const sampleSize = 100
+
+	checked := 0
+	for _, guessInfo := range m {
+		if checked == sampleSize {
+			break
+		}
+		checked++
+
+		for _, hintInfo := range guessInfo.HintsMap {
+			// lazily built bitvecs aren't in the cache at all
+			if hintInfo.Bitvec != nil && !hintInfo.Bitvec.VerifyCount() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// saveAttempts is how many times saveGuessesMap tries to write the cache
+// before giving up, waiting a little longer after each failure
+const saveAttempts = 3
+
+// saveGuessesMap writes m to guesses_cache.gob. Nothing may be reading m while
+// it's saved, since lookups fill in lazily built bitvecs as they go, so a map
+// that replaces guessesMap has to be saved before it's published. It gives up
+// with the last error once saveAttempts writes have failed.
+func saveGuessesMap(m map[string]*GuessInfo) error {
 	start := time.Now()
 
+	var err error
+	for attempt := range saveAttempts {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+		}
+		if err = writeGuessesCache("guesses_cache.gob", m); err == nil {
+			fmt.Fprintf(os.Stderr, "Saved guesses cache to disk in %v\n", time.Since(start))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("saving cache after %d attempts: %w", saveAttempts, err)
+}
+
+// writeGuessesCache writes m to a temp file next to path and then renames it
+// over path, so a failed write never leaves a partial cache behind. Like
+// saveGuessesMap, nothing may be reading m.
+func writeGuessesCache(path string, m map[string]*GuessInfo) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("creating cache file: %w", err)
+	}
+	// does nothing once the rename has happened
+	defer os.Remove(file.Name())
+	// CreateTemp makes the file 0600, but the cache is as public as the word
+	// lists it's built from
+	if err := file.Chmod(0o644); err != nil {
+		file.Close()
+		return fmt.Errorf("creating cache file: %w", err)
+	}
+
 	encoder := gob.NewEncoder(file)
-	err = encoder.Encode(guessesMap)
+	err = encoder.Encode(guessesCache{
+		WordLen:       WordLen,
+		NumAnswers:    len(answers),
+		AnswersHash:   answersHash(),
+		HintMode:      CurrentHintMode,
+		HintBase:      HintBase(),
+		CaseSensitive: CaseSensitive,
+		GuessesMap:    m,
+	})
 	if err != nil {
-		fmt.Println("Error encoding cache:", err)
-		return
+		file.Close()
+		return fmt.Errorf("encoding cache: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writing cache file: %w", err)
 	}
 
-	fmt.Printf("Saved guesses cache to disk in %v\n", time.Since(start))
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("replacing cache file: %w", err)
+	}
+
+	return nil
 }
 
 func main() {
+	if os.Getenv(profileEnv) != "" {
+		Profile = true
+	}
+
+	err := run(os.Args[1:])
+	if Profile {
+		PrintProfile()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run is everything main does before it exits, so the profile is printed
+// however it ends
+func run(args []string) error {
+	// a repeat breaks the cache build, but a cache built before it crept in
+	// would still load, so always check
+	inGuesses, inAnswers := DuplicateWords()
+	if len(inGuesses) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: io/guesses.txt has duplicates: %v\n", strings.Join(inGuesses, ", "))
+	}
+	if len(inAnswers) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: io/answers.txt has duplicates: %v\n", strings.Join(inAnswers, ", "))
+	}
+
+	if err := buildGuessesMap(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		return runCommand(args, os.Stdout)
+	}
+
+	printWordHints("roate")
+
+	// findBestGuess(context.Background())
+
+	return nil
+}
+
+// buildGuessesMap calculates guessesMap and saves it to disk if it wasn't
+// loaded from disk. It fails rather than carrying on with an empty map, which
+// would make everything after it quietly do nothing.
+func buildGuessesMap() error {
 	// run these functions if guessesMap was not loaded from disk
 	if len(guessesMap) == 0 {
-		calculateHints()
-		calculateBitvecs()
+		if len(guesses) == 0 || len(answers) == 0 {
+			return fmt.Errorf("can't build the cache: %d guesses and %d answers", len(guesses), len(answers))
+		}
+
+		// bad words would silently corrupt the hints, so refuse to build
+		var problems []string
+		for _, list := range []struct {
+			file  string
+			words []string
+		}{{"io/guesses.txt", guesses}, {"io/answers.txt", answers}} {
+			for _, problem := range ValidateWordList(list.words) {
+				problems = append(problems, list.file+" "+problem)
+			}
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("can't build the cache, the word lists have problems:\n%s", strings.Join(problems, "\n"))
+		}
+
+		fmt.Fprintf(os.Stderr, "Building the cache should take about %v\n", EstimateBuildTime().Round(time.Second))
+		if err := calculateHints(context.Background()); err != nil {
+			return fmt.Errorf("calculating hints: %w", err)
+		}
+		if !LazyBitvecs {
+			calculateBitvecs()
+		}
 		// calculateHintGuesses()
 		// save guessesMap to disk if needed
-		saveGuessesMap()
+		if err := saveGuessesMap(guessesMap); err != nil {
+			return err
+		}
 	}
 
-	printWordHints("roate")
+	if len(guessesMap) == 0 {
+		return errors.New("no guesses in the cache after building it")
+	}
+
+	return nil
+}
+
+// EstimateBuildTime guesses how long calculateHints will take by timing
+// getHint on a sample of guess-answer pairs and scaling up to every pair,
+// spread over the available CPUs. It ignores the map writes, so treat it as a
+// lower bound.
+func EstimateBuildTime() time.Duration {
+	const sampleSize = 10000
+
+	if len(guesses) == 0 || len(answers) == 0 {
+		return 0
+	}
+
+	start := time.Now()
+	for i := range sampleSize {
+		getHint(guesses[i%len(guesses)], answers[i%len(answers)])
+	}
+	perHint := float64(time.Since(start)) / sampleSize
 
-	// findBestGuess()
+	numPairs := float64(len(guesses)) * float64(len(answers))
+	return time.Duration(perHint * numPairs / float64(runtime.GOMAXPROCS(0)))
+}
+
+// UpdateCache brings guesses_cache.gob up to date with the guess list without
+// rebuilding it: guesses missing from the cache get their hints and bitvecs
+// calculated, guesses no longer in the list get dropped, and everything else
+// is reused. If the answer list changed the whole cache is rebuilt, since
+// every bitvec depends on it. The updated map is built on the side and
+// swapped in at the end, so it's safe to call while other goroutines solve.
+func UpdateCache() {
+	updated := loadGuessesMap()
+
+	inList := make(map[string]bool, len(guesses))
+	for _, guess := range guesses {
+		inList[guess] = true
+	}
+
+	removed := 0
+	for guess := range updated {
+		if !inList[guess] {
+			delete(updated, guess)
+			removed++
+		}
+	}
+
+	var added []string
+	for _, guess := range guesses {
+		if updated[guess] == nil {
+			added = append(added, guess)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Updating cache: %d new guesses, %d removed\n", len(added), removed)
+	if len(added) == 0 && removed == 0 {
+		setGuessesMap(updated)
+		return
+	}
+
+	if len(added) > 0 {
+		if err := calculateHintsFor(context.Background(), updated, added, answers); err != nil {
+			fmt.Fprintln(os.Stderr, "Error calculating hints:", err)
+			return
+		}
+		if !LazyBitvecs {
+			calculateBitvecsFor(updated, added, answers)
+		}
+	}
+
+	// save before publishing, since once updated is published lookups can
+	// fill in its lazy bitvecs while the encoder is reading them
+	if err := saveGuessesMap(updated); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	setGuessesMap(updated)
 }
 
 func calculateHintGuesses() {
 	panic("unimplemented")
 }
 
-func calculateHints() {
-	fmt.Println("calculating hints for all guess-answer pairs")
-	bar := progressbar.Default(int64(len(guesses)))
+// calculateHints fills in guessesMap with the hint for every guess-answer pair.
+// If ctx is cancelled it stops early, leaving guessesMap partly filled, and
+// returns ctx.Err(). It writes to guessesMap in place, so it's only for
+// startup; use UpdateCache once anything else is reading.
+func calculateHints(ctx context.Context) error {
+	return calculateHintsFor(ctx, guessesMap, guesses, answers)
+}
+
+// calculateHintsFor is calculateHints for just the given guesses against
+// answerList, filling in target instead of guessesMap
+func calculateHintsFor(ctx context.Context, target map[string]*GuessInfo, words, answerList []string) error {
+	fmt.Fprintln(os.Stderr, "calculating hints for", len(words), "guesses")
+	bar := newProgress(len(words))
 
 	var wg sync.WaitGroup
+	limit := newWorkerLimit()
 
-	for _, guess := range guesses {
+	for _, guess := range words {
 		answerHints := make(map[string]Hint)
 		hintsMap := make(map[Hint]*HintInfo)
 
-		guessesMap[guess] = &GuessInfo{
+		target[guess] = &GuessInfo{
 			answerHints,
 			hintsMap,
 		}
 
 		wg.Add(1)
+		limit <- struct{}{}
 
 		go func() {
 			defer wg.Done()
-			for _, answer := range answers {
+			defer func() { <-limit }()
+			defer trackWorker()()
+			for _, answer := range answerList {
+				if ctx.Err() != nil {
+					return
+				}
 				hint := getHint(guess, answer)
 				answerHints[answer] = hint
 
 				if hintsMap[hint] == nil {
-					hintsMap[hint] = &HintInfo{
-						Bitvec: NewBitvec(len(answers)),
+					hintsMap[hint] = &HintInfo{}
+					if !LazyBitvecs {
+						hintsMap[hint].Bitvec = NewBitvec(len(answerList))
 					}
 				}
 			}
@@ -127,26 +596,42 @@ func calculateHints() {
 	}
 
 	wg.Wait()
+
+	return ctx.Err()
 }
 
 func calculateBitvecs() {
+	calculateBitvecsFor(guessesMap, guesses, answers)
+}
+
+// calculateBitvecsFor is calculateBitvecs for just the given guesses in target,
+// against answerList. Bitvecs calculateHintsFor left unset are built here.
+func calculateBitvecsFor(target map[string]*GuessInfo, words, answerList []string) {
 	numUniqueHints := 0
-	for _, guessInfo := range guessesMap {
-		numUniqueHints += len(guessInfo.HintsMap)
+	for _, guess := range words {
+		numUniqueHints += len(target[guess].HintsMap)
 	}
 
-	fmt.Println("calculating bitvecs for", numUniqueHints, "unique hints")
-	bar := progressbar.Default(int64(numUniqueHints))
+	fmt.Fprintln(os.Stderr, "calculating bitvecs for", numUniqueHints, "unique hints")
+	bar := newProgress(numUniqueHints)
 
 	var wg sync.WaitGroup
+	limit := newWorkerLimit()
 
-	for _, guessInfo := range guessesMap {
+	for _, guess := range words {
+		guessInfo := target[guess]
 		wg.Add(1)
+		limit <- struct{}{}
 		go func() {
 			defer wg.Done()
+			defer func() { <-limit }()
+			defer trackWorker()()
 			for hint, hintInfo := range guessInfo.HintsMap {
 				bar.Add(1)
-				for answerIdx, answer := range answers {
+				if hintInfo.Bitvec == nil {
+					hintInfo.Bitvec = NewBitvec(len(answerList))
+				}
+				for answerIdx, answer := range answerList {
 					hint2 := guessInfo.AnswerHints[answer]
 					if hint2 == hint {
 						hintInfo.Bitvec.Set(answerIdx)
@@ -160,58 +645,67 @@ func calculateBitvecs() {
 	wg.Wait()
 }
 
-func findBestGuess() {
-	fmt.Printf("Finding best guess pair\n")
+// findBestGuess searches every pair of disjoint unique-letter guesses for the
+// one with the lowest AvgNumCandidates. If ctx is cancelled it stops early and
+// returns ctx.Err() without printing a result.
+func findBestGuess(ctx context.Context) error {
+	fmt.Fprintf(os.Stderr, "Finding best guess pair\n")
 
-	guessBitvecs := []*Bitvec{}
-	filteredGuesses := []string{}
-
-	for _, guess := range guesses {
-		bitvec := NewBitvec(26)
+	bestGuess1, bestGuess2, bestGuessVal, err := bestGuessPair(ctx)
+	if err != nil {
+		return err
+	}
 
-		for i := range 5 {
-			j := int(guess[i] - 'a')
-			bitvec.Set(j)
-		}
+	fmt.Fprintf(os.Stderr, "Done, best guess pair: %v, %v (%.2f)\n", bestGuess1, bestGuess2, bestGuessVal)
+	return nil
+}
 
-		if bitvec.Count == 5 {
-			guessBitvecs = append(guessBitvecs, bitvec)
-			filteredGuesses = append(filteredGuesses, guess)
-		}
+// bestGuessPair returns the pair of guesses with no letters in common that
+// leaves the fewest candidates on average, along with that average. Ties go
+// to the pair that comes first in list order, so the result doesn't depend on
+// how the workers are scheduled.
+func bestGuessPair(ctx context.Context) (string, string, float64, error) {
+	filteredGuesses, guessBitvecs := uniqueLetterGuesses()
+	if len(filteredGuesses) < 2 {
+		return "", "", 0, errors.New("need at least 2 guesses with unique letters")
 	}
 
-	totalPairs := int64(len(filteredGuesses) * (len(filteredGuesses) - 1) / 2)
-	fmt.Printf("filtered down to %v guesses with 5 unique letters (%v pairs)\n", len(filteredGuesses), totalPairs)
+	totalPairs := len(filteredGuesses) * (len(filteredGuesses) - 1) / 2
+	fmt.Fprintf(os.Stderr, "filtered down to %v guesses with %v unique letters (%v pairs)\n", len(filteredGuesses), WordLen, totalPairs)
 
-	bar := progressbar.Default(totalPairs)
+	bar := newProgress(totalPairs)
 
-	bestGuess1 := filteredGuesses[0]
-	bestGuess2 := filteredGuesses[1]
-	bestGuessVal := AvgNumCandidates(bestGuess1, bestGuess2)
+	best1, best2 := 0, 1
+	bestGuessVal := AvgNumCandidates(filteredGuesses[best1], filteredGuesses[best2])
 
 	mu := sync.Mutex{}
 	wg := sync.WaitGroup{}
+	limit := newWorkerLimit()
 
 	for i := range len(filteredGuesses) - 1 {
 		wg.Add(1)
+		limit <- struct{}{}
 		go func() {
 			defer wg.Done()
+			defer func() { <-limit }()
+			defer trackWorker()()
 			for j := i + 1; j < len(filteredGuesses); j++ {
-				guess1 := filteredGuesses[i]
-				guess2 := filteredGuesses[j]
+				if ctx.Err() != nil {
+					return
+				}
 
-				if guessBitvecs[i].And(guessBitvecs[j]).Count != 0 {
+				if guessBitvecs[i].Intersects(guessBitvecs[j]) {
 					bar.Add(1)
 					continue
 				}
 
-				guessVal := AvgNumCandidates(guess1, guess2)
+				guessVal := AvgNumCandidates(filteredGuesses[i], filteredGuesses[j])
 				mu.Lock()
-				if guessVal < bestGuessVal {
-					bestGuess1 = guess1
-					bestGuess2 = guess2
+				if guessVal < bestGuessVal ||
+					(guessVal == bestGuessVal && (i < best1 || (i == best1 && j < best2))) {
+					best1, best2 = i, j
 					bestGuessVal = guessVal
-					bar.Describe(fmt.Sprintf("Best: %v, %v (%.2f)", bestGuess1, bestGuess2, bestGuessVal))
+					bar.Describe(fmt.Sprintf("Best: %v, %v (%.2f)", filteredGuesses[best1], filteredGuesses[best2], bestGuessVal))
 				}
 				mu.Unlock()
 				bar.Add(1)
@@ -221,74 +715,242 @@ func findBestGuess() {
 
 	wg.Wait()
 
-	fmt.Printf("Done, best guess pair: %v, %v (%.2f)\n", bestGuess1, bestGuess2, bestGuessVal)
+	if err := ctx.Err(); err != nil {
+		return "", "", 0, err
+	}
+
+	return filteredGuesses[best1], filteredGuesses[best2], bestGuessVal, nil
 }
 
+// uniqueLetterGuesses returns the guesses with WordLen distinct letters along
+// with a letter set over Alphabet for each one. Words that aren't WordLen
+// letters from Alphabet are skipped since they'd index outside the letter set.
+func uniqueLetterGuesses() ([]string, []*Bitvec) {
+	allowed := allowedGuesses()
+	guessBitvecs := []*Bitvec{}
+	filteredGuesses := []string{}
+
+	for _, guess := range allowed {
+		if len(guess) != WordLen {
+			continue
+		}
+
+		bitvec := NewBitvec(len(Alphabet))
+
+		for i := range WordLen {
+			idx := letterIndex(guess[i])
+			if idx == -1 {
+				break
+			}
+			bitvec.Set(idx)
+		}
+
+		if bitvec.Count == WordLen {
+			guessBitvecs = append(guessBitvecs, bitvec)
+			filteredGuesses = append(filteredGuesses, guess)
+		}
+	}
+
+	return filteredGuesses, guessBitvecs
+}
+
+// GetHint is getHint for words that haven't been checked yet, e.g. user input.
+// It returns an error instead of panicking if either word is the wrong length.
+func GetHint(guess, answer string) (Hint, error) {
+	if err := checkWord(guess); err != nil {
+		return 0, err
+	}
+	if err := checkWord(answer); err != nil {
+		return 0, err
+	}
+
+	return getHint(guess, answer), nil
+}
+
+// getHint works on bytes, not runes, so both words have to be ASCII. A
+// multibyte letter would throw off the positions, which is why
+// ValidateWordList only lets a to z through. Both words must be WordLen
+// letters; use GetHint for unchecked input.
 func getHint(guess, answer string) Hint {
-	var charHints [5]uint8
+	if Profile {
+		defer recordProfile("getHint", time.Now())
+	}
+
+	if !CaseSensitive {
+		// ToLower doesn't allocate for words that are already lower case
+		guess, answer = strings.ToLower(guess), strings.ToLower(answer)
+	}
+
+	var charHints [WordLen]uint8
 
-	for i, ch := range guess {
-		if answer[i] == byte(ch) {
+	for i := range len(guess) {
+		ch := guess[i]
+		if answer[i] == ch {
 			charHints[i] = 2
-		} else if strings.ContainsRune(answer, ch) {
+		} else if CurrentHintMode != GreensOnly && strings.IndexByte(answer, ch) != -1 {
 			charHints[i] = 1
 		}
 	}
 
-	var ret uint8
+	var ret Hint
 	for _, d := range charHints {
-		ret = (ret * 3) + d
+		ret = ret*Hint(HintBase()) + Hint(d)
+	}
+
+	return ret
+}
+
+// SelfHint is the hint word gets against itself, which is always allGreen.
+// It panics if it isn't, since that means word is the wrong length or
+// getHint is broken, so it doubles as a sanity check on the word lists.
+func SelfHint(word string) Hint {
+	if err := checkWord(word); err != nil {
+		panic("SelfHint: " + err.Error())
+	}
+
+	hint := getHint(word, word)
+	if hint != allGreen() {
+		panic(fmt.Sprintf("SelfHint: '%s' got %v against itself", word, hint))
+	}
+
+	return hint
+}
+
+// HintsFor returns the hint guess gets against each of answers, in order,
+// without needing guessesMap. Returns nil if guess or any of answers is the
+// wrong length.
+func HintsFor(guess string, answers []string) []Hint {
+	if checkWord(guess) != nil {
+		return nil
+	}
+
+	hints := make([]Hint, len(answers))
+	for i, answer := range answers {
+		if checkWord(answer) != nil {
+			return nil
+		}
+		hints[i] = getHint(guess, answer)
+	}
+
+	return hints
+}
+
+// HintsForAnswer maps every guess to the hint it gets against answer, the
+// transpose of each guess's AnswerHints. Uses the cached hints when they're
+// there. Returns nil if answer is the wrong length.
+func HintsForAnswer(answer string) map[string]Hint {
+	if checkWord(answer) != nil {
+		return nil
+	}
+
+	hints := make(map[string]Hint, len(guesses))
+	for _, guess := range guesses {
+		if guessInfo := lookupGuessInfo(guess); guessInfo != nil {
+			if hint, ok := guessInfo.AnswerHints[answer]; ok {
+				hints[guess] = hint
+				continue
+			}
+		}
+		hints[guess] = getHint(guess, answer)
 	}
 
-	return Hint(ret)
+	return hints
 }
 
 func lookupBitvec(guess, answer string) *Bitvec {
-	answerHints := guessesMap[guess].AnswerHints
-	hintsMap := guessesMap[guess].HintsMap
-	return hintsMap[answerHints[answer]].Bitvec
+	s := defaultSolver()
+	return s.lookupBitvec(guess, answer)
 }
 
 func (h Hint) String() string {
-	hintReplacer := strings.NewReplacer("0", "⬜", "1", "🟨", "2", "🟩")
-	base3Str := strconv.FormatUint(uint64(h), 3)
-	paddedBase3Str := fmt.Sprintf("%05s", base3Str)
+	hintReplacer := strings.NewReplacer("0", "⬜", "1", "🟨", "2", "🟩", "3", "🟪")
+	return hintReplacer.Replace(h.Digits())
+}
 
-	return hintReplacer.Replace(paddedBase3Str)
+// Digits returns the hint as WordLen base HintBase digits, e.g. "20100"
+func (h Hint) Digits() string {
+	var digits strings.Builder
+	for _, d := range h.Sequence() {
+		digits.WriteByte(byte('0' + d))
+	}
+
+	return digits.String()
 }
 
+// Sequence returns the hint's digits, first letter first: 0 for gray, 1 for
+// yellow, and 2 for green
+func (h Hint) Sequence() [WordLen]int {
+	var seq [WordLen]int
+	for i := WordLen - 1; i >= 0; i-- {
+		seq[i] = int(h % Hint(HintBase()))
+		h /= Hint(HintBase())
+	}
+
+	return seq
+}
+
+// HintFromSequence is the inverse of Sequence. It panics if a digit isn't
+// below HintBase; use hintFromDigits for unchecked input.
+func HintFromSequence(seq [WordLen]int) Hint {
+	var hint Hint
+	for _, d := range seq {
+		if d < 0 || d >= HintBase() {
+			panic(fmt.Sprintf("HintFromSequence: digit %d out of range", d))
+		}
+		hint = hint*Hint(HintBase()) + Hint(d)
+	}
+
+	return hint
+}
+
+// HintPalette is the ANSI codes used to color gray, yellow, and green letters,
+// and the extra tile state when HintBase is 4, indexed by hint digit
+type HintPalette [4]string
+
+// ANSI color codes
+var (
+	StandardPalette = HintPalette{
+		"\033[48;5;236m\033[38;5;255m", // gray background, white text
+		"\033[43m\033[30m",             // yellow background, black text
+		"\033[42m\033[30m",             // green background, black text
+		"\033[45m\033[30m",             // purple background, black text
+	}
+	// HighContrastPalette is NYT's high contrast mode: blue for wrong position
+	// and orange for correct position
+	HighContrastPalette = HintPalette{
+		"\033[48;5;236m\033[38;5;255m", // gray background, white text
+		"\033[48;5;39m\033[30m",        // blue background, black text
+		"\033[48;5;208m\033[30m",       // orange background, black text
+		"\033[45m\033[30m",             // purple background, black text
+	}
+)
+
+// ColorblindPalette is the palette ColorblindWord uses
+var ColorblindPalette = HighContrastPalette
+
 // ColoredWord displays a word with colored backgrounds based on the hint
 func (h Hint) ColoredWord(word string) string {
-	if len(word) != 5 {
-		return word // Return unchanged if not 5 characters
-	}
+	return h.paletteWord(word, StandardPalette)
+}
 
-	// ANSI color codes
-	const (
-		reset    = "\033[0m"
-		grayBg   = "\033[48;5;236m\033[38;5;255m" // gray background, white text
-		yellowBg = "\033[43m\033[30m"             // yellow background, black text
-		greenBg  = "\033[42m\033[30m"             // green background, black text
-	)
+// ColorblindWord is ColoredWord with ColorblindPalette, for users who can't
+// tell green and yellow apart
+func (h Hint) ColorblindWord(word string) string {
+	return h.paletteWord(word, ColorblindPalette)
+}
 
-	// Convert hint back to individual digits
-	hintValue := uint64(h)
-	var digits [5]int
-	for i := 4; i >= 0; i-- {
-		digits[i] = int(hintValue % 3)
-		hintValue /= 3
+func (h Hint) paletteWord(word string, palette HintPalette) string {
+	if len(word) != WordLen {
+		return word // Return unchanged if not WordLen characters
 	}
 
+	const reset = "\033[0m"
+
+	digits := h.Sequence()
+
 	var result strings.Builder
 	for i, char := range word {
-		switch digits[i] {
-		case 0: // No match
-			result.WriteString(grayBg)
-		case 1: // Wrong position
-			result.WriteString(yellowBg)
-		case 2: // Correct position
-			result.WriteString(greenBg)
-		}
+		result.WriteString(palette[digits[i]])
 		result.WriteRune(char)
 		result.WriteString(" ") // Add space between letters
 		result.WriteString(reset)
@@ -298,47 +960,85 @@ func (h Hint) ColoredWord(word string) string {
 }
 
 func AvgNumCandidates(firstGuess string, guesses ...string) float64 {
-	var tot float64
+	if Profile {
+		defer recordProfile("AvgNumCandidates", time.Now())
+	}
 
-	for _, answer := range answers {
-		bitvec := lookupBitvec(firstGuess, answer)
-		broke := false
+	s := defaultSolver()
+	return s.AvgNumCandidates(firstGuess, guesses...)
+}
 
-		for _, guess := range guesses {
-			if bitvec.Count <= 2 {
-				broke = true
-				tot += 1.0
-				break
-			}
-			bitvec = bitvec.And(lookupBitvec(guess, answer))
-		}
+// AvgNumCandidatesParallel is AvgNumCandidates split across workers
+// goroutines. The total is kept as an integer and divided once at the end, so
+// the result is exactly the same for any number of workers.
+func AvgNumCandidatesParallel(workers int, firstGuess string, guesses ...string) float64 {
+	workers = max(min(workers, len(answers)), 1)
+	if workers == 1 {
+		// no goroutines needed, and this is called a lot from the pair search
+		return float64(sumNumCandidates(answers, firstGuess, guesses)) / float64(len(answers))
+	}
 
-		if !broke {
-			tot += float64(bitvec.Count)
-		}
+	var tot atomic.Int64
+
+	wg := sync.WaitGroup{}
+
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tot.Add(sumNumCandidates(answers[w*len(answers)/workers:(w+1)*len(answers)/workers], firstGuess, guesses))
+		}()
 	}
 
-	return tot / float64(len(answers))
+	wg.Wait()
+
+	return float64(tot.Load()) / float64(len(answers))
+}
+
+// sumNumCandidates adds up how many candidates are left for each of
+// someAnswers after playing the guesses, counting 1 once there are 2 or fewer
+func sumNumCandidates(someAnswers []string, firstGuess string, guesses []string) int64 {
+	s := defaultSolver()
+	return s.sumNumCandidates(someAnswers, firstGuess, guesses)
+}
+
+// numCandidatesLeft is how many candidates are left for answer after playing
+// the guesses, or 1 once there are 2 or fewer. scratch is used for the
+// intermediate results.
+func numCandidatesLeft(answer, firstGuess string, guesses []string, scratch *Bitvec) int {
+	s := defaultSolver()
+	return s.numCandidatesLeft(answer, firstGuess, guesses, scratch)
 }
 
 func printWordHints(word string) {
+	writeWordHints(os.Stdout, word)
+}
+
+// writeWordHints writes each hint word can get, colored, with how many answers
+// give it, most common first
+func writeWordHints(w io.Writer, word string) {
 	type HintCount struct {
 		hint  Hint
 		count int
 	}
 
 	var hintCounts []HintCount
-	for hint, hintInfo := range guessesMap[word].HintsMap {
-		hintCounts = append(hintCounts, HintCount{hint, hintInfo.Bitvec.Count})
+	guessInfo := lookupGuessInfo(word)
+	for hint := range guessInfo.HintsMap {
+		hintCounts = append(hintCounts, HintCount{hint, guessInfo.hintBitvec(hint).Count})
 	}
 
-	// Sort by count in descending order (high to low)
+	// Sort by count in descending order (high to low), then by hint so equal
+	// counts always come out in the same order
 	sort.Slice(hintCounts, func(i, j int) bool {
-		return hintCounts[i].count > hintCounts[j].count
+		if hintCounts[i].count != hintCounts[j].count {
+			return hintCounts[i].count > hintCounts[j].count
+		}
+		return hintCounts[i].hint < hintCounts[j].hint
 	})
 
 	// Print sorted results
 	for _, hc := range hintCounts {
-		fmt.Println(hc.hint.ColoredWord(word), hc.count)
+		fmt.Fprintln(w, hc.hint.ColoredWord(word), hc.count)
 	}
 }
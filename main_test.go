@@ -0,0 +1,1001 @@
+package main
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// runMainEnv makes the test binary run main with its arguments instead of the
+// tests, for runMain
+const runMainEnv = "WORDLE_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runMain runs the program the way a user would, through main in a fresh
+// process with args on the command line and env added to the environment.
+// It runs in a temp directory with the fixture lists as io/, so it builds
+// its own cache there. Returns what was written to stdout and stderr, and
+// the error if it didn't exit cleanly.
+func runMain(t *testing.T, env []string, args ...string) (string, string, error) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "io"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"guesses.txt", "answers.txt"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// main's lists have no trailing newline, or it would be an empty word
+		words := strings.Join(strings.Fields(string(data)), "\n")
+		if err := os.WriteFile(filepath.Join(dir, "io", name), []byte(words), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr strings.Builder
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), runMainEnv+"=1"), env...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+
+	return stdout.String(), stderr.String(), err
+}
+
+// useFixture switches to the small word lists in testdata and builds their
+// hints and bitvecs in memory. Nothing is written to disk.
+func useFixture(t testing.TB) {
+	t.Helper()
+	useWordLists(t, "testdata/guesses.txt", "testdata/answers.txt")
+}
+
+// useWordLists is useFixture for other lists
+func useWordLists(t testing.TB, guessesPath, answersPath string) {
+	t.Helper()
+
+	setForTest(t, &ProgressFunc, func(done, total int) {})
+	if err := LoadWordLists(guessesPath, answersPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := calculateHints(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	calculateBitvecs()
+}
+
+// setForTest sets a package option for the rest of the test and puts the old
+// value back afterwards
+func setForTest[T any](t testing.TB, opt *T, val T) {
+	t.Helper()
+
+	old := *opt
+	*opt = val
+	t.Cleanup(func() { *opt = old })
+}
+
+// countWorkers swaps out trackWorker for the rest of the test and returns a
+// function reporting the most workers that were running at once
+func countWorkers(t testing.TB) func() int32 {
+	var inFlight, peak atomic.Int32
+	setForTest(t, &trackWorker, func() func() {
+		n := inFlight.Add(1)
+		for old := peak.Load(); n > old; old = peak.Load() {
+			if peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		return func() { inFlight.Add(-1) }
+	})
+
+	return peak.Load
+}
+
+// fixtureBitvec returns the fixture answers in words as a candidate set
+func fixtureBitvec(t testing.TB, words ...string) *Bitvec {
+	t.Helper()
+
+	set := NewWordSet(answers)
+	for _, word := range words {
+		if !set.Add(word) {
+			t.Fatalf("%q isn't a fixture answer", word)
+		}
+	}
+
+	return set.bv
+}
+
+// writeTestCache saves guessesMap to guesses_cache.gob in a fresh temp
+// directory and makes that the working directory for the rest of the test
+func writeTestCache(t testing.TB) {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+
+	guessesMapMu.RLock()
+	defer guessesMapMu.RUnlock()
+	if err := writeGuessesCache("guesses_cache.gob", guessesMap); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadGuessesMapRebuildsForMoreAnswers(t *testing.T) {
+	useFixture(t)
+	fullAnswers := answers
+
+	// a cache from before the last 5 answers were added
+	SetWordLists(guesses, fullAnswers[:len(fullAnswers)-5])
+	if err := calculateHints(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	calculateBitvecs()
+	writeTestCache(t)
+
+	if loaded := loadGuessesMap(); len(loaded) != len(guesses) {
+		t.Fatalf("cache for the same answers loaded %d guesses, want %d", len(loaded), len(guesses))
+	}
+
+	SetWordLists(guesses, fullAnswers)
+	if loaded := loadGuessesMap(); len(loaded) != 0 {
+		t.Fatalf("cache for %d answers loaded against %d", len(fullAnswers)-5, len(fullAnswers))
+	}
+
+	if err := buildGuessesMap(); err != nil {
+		t.Fatal(err)
+	}
+	for _, guess := range guesses {
+		for i, answer := range answers {
+			if !lookupBitvec(guess, answer).Get(i) {
+				t.Fatalf("rebuilt bitvec for %v doesn't have %v", guess, answer)
+			}
+		}
+	}
+}
+
+// cancelAfterChecks is a context that cancels itself the nth time Err is
+// checked, so whatever it's passed to gets stopped partway through
+type cancelAfterChecks struct {
+	context.Context
+	cancel context.CancelFunc
+	checks atomic.Int64
+	n      int64
+}
+
+func (c *cancelAfterChecks) Err() error {
+	if c.checks.Add(1) == c.n {
+		c.cancel()
+	}
+	return c.Context.Err()
+}
+
+func TestCancelMidComputation(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		{"calculateHints", calculateHints},
+		{"findBestGuess", findBestGuess},
+	} {
+		useFixture(t)
+
+		inner, cancel := context.WithCancel(context.Background())
+		ctx := &cancelAfterChecks{Context: inner, cancel: cancel, n: 5}
+
+		start := time.Now()
+		err := tt.run(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%v returned %v, want context.Canceled", tt.name, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%v took %v to stop", tt.name, elapsed)
+		}
+		cancel()
+	}
+}
+
+func TestColorblindWord(t *testing.T) {
+	digits := []int{2, 1, 0, 2, 1}
+	hint, err := hintFromDigits(digits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := hint.ColorblindWord("crane")
+
+	var want strings.Builder
+	for i, d := range digits {
+		want.WriteString(HighContrastPalette[d])
+		want.WriteByte("crane"[i])
+		want.WriteString(" \033[0m")
+	}
+	if got != want.String() {
+		t.Errorf("ColorblindWord() = %q, want %q", got, want.String())
+	}
+
+	// the standard palette's green and yellow shouldn't show up at all
+	for _, d := range []int{1, 2} {
+		if strings.Contains(got, StandardPalette[d]) {
+			t.Errorf("ColorblindWord() uses the standard color for %d", d)
+		}
+	}
+}
+
+func TestEstimateBuildTime(t *testing.T) {
+	useFixture(t)
+
+	// the fastest of a few runs, to keep timing noise out of the comparison
+	estimate := func() time.Duration {
+		best := EstimateBuildTime()
+		for range 4 {
+			best = min(best, EstimateBuildTime())
+		}
+		return best
+	}
+
+	small := estimate()
+	if small <= 0 {
+		t.Fatalf("EstimateBuildTime() = %v, want it positive", small)
+	}
+
+	// 16 times as many pairs should take about 16 times as long
+	repeat := func(words []string) []string {
+		return slices.Concat(words, words, words, words)
+	}
+	SetWordLists(repeat(guesses), repeat(answers))
+	large := estimate()
+	if ratio := float64(large) / float64(small); ratio < 4 || ratio > 64 {
+		t.Errorf("16 times the pairs estimated %v against %v, a ratio of %.2f", large, small, ratio)
+	}
+}
+
+func TestUpdateCache(t *testing.T) {
+	useFixture(t)
+	allGuesses := guesses
+
+	SetWordLists(allGuesses[:10], answers)
+	if err := calculateHints(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	calculateBitvecs()
+	// mark the cached guesses with a hint for a word that isn't an answer, which
+	// only survives if UpdateCache reuses them instead of calculating them again
+	for _, guess := range guesses {
+		guessesMap[guess].AnswerHints["zzzzz"] = 0
+	}
+	writeTestCache(t)
+
+	SetWordLists(allGuesses[:12], answers)
+	UpdateCache()
+
+	for i, guess := range guesses {
+		_, reused := guessesMap[guess].AnswerHints["zzzzz"]
+		if reused != (i < 10) {
+			t.Errorf("%v was reused = %v, want only the 10 cached guesses reused", guess, reused)
+		}
+	}
+
+	for _, m := range []map[string]*GuessInfo{guessesMap, loadGuessesMap()} {
+		if len(m) != 12 {
+			t.Fatalf("cache has %d guesses, want 12", len(m))
+		}
+		for _, guess := range guesses {
+			for i, answer := range answers {
+				guessInfo := m[guess]
+				if !guessInfo.hintBitvec(guessInfo.AnswerHints[answer]).Get(i) {
+					t.Errorf("%v's bitvec for %v is wrong", guess, answer)
+				}
+			}
+		}
+	}
+}
+
+func TestHintStringRoundTrip(t *testing.T) {
+	for h := Hint(0); h <= allGreen(); h++ {
+		if n := len([]rune(h.String())); n != WordLen {
+			t.Errorf("%d.String() = %q has %d tiles, want %d", h, h.String(), n, WordLen)
+		}
+
+		seq := h.Sequence()
+		if got := HintFromSequence(seq); got != h {
+			t.Errorf("HintFromSequence(%v) = %d, want %d", seq, got, h)
+		}
+
+		var digits []int
+		for _, ch := range h.Digits() {
+			digits = append(digits, int(ch-'0'))
+		}
+		if got, err := hintFromDigits(digits); err != nil || got != h {
+			t.Errorf("hintFromDigits(%q) = %d, %v, want %d", h.Digits(), got, err, h)
+		}
+	}
+
+	if got := allGreen().String(); got != strings.Repeat("🟩", WordLen) {
+		t.Errorf("allGreen().String() = %q", got)
+	}
+}
+
+func TestGreensOnly(t *testing.T) {
+	useFixture(t)
+
+	if got := getHint("taker", "crate"); got.Digits() != "11011" {
+		t.Fatalf("Standard getHint(taker, crate) = %s, want 11011", got.Digits())
+	}
+	setForTest(t, &CurrentHintMode, GreensOnly)
+	if got := getHint("taker", "crate"); got.Digits() != "00000" {
+		t.Errorf("GreensOnly getHint(taker, crate) = %s, want 00000", got.Digits())
+	}
+
+	for _, guess := range guesses {
+		for _, answer := range answers {
+			CurrentHintMode = Standard
+			standard := getHint(guess, answer).Digits()
+			CurrentHintMode = GreensOnly
+			greensOnly := getHint(guess, answer).Digits()
+
+			if want := strings.ReplaceAll(standard, "1", "0"); greensOnly != want {
+				t.Errorf("GreensOnly getHint(%s, %s) = %s, want %s", guess, answer, greensOnly, want)
+			}
+		}
+	}
+}
+
+func TestUniqueLetterGuessesSkipsNonLetters(t *testing.T) {
+	useWordLists(t, "testdata/oddguesses.txt", "testdata/answers.txt")
+
+	got, letterSets := uniqueLetterGuesses()
+	if want := []string{"baker", "fmnst"}; !slices.Equal(got, want) {
+		t.Errorf("uniqueLetterGuesses() = %q, want %q", got, want)
+	}
+	for i, letters := range letterSets {
+		if letters.Size != len(Alphabet) || letters.Count != WordLen {
+			t.Errorf("%s has letter set %v", got[i], letters)
+		}
+	}
+}
+
+func TestHintsFor(t *testing.T) {
+	// no fixture, since HintsFor shouldn't need one
+	someAnswers := []string{"crane", "tight", "steal", "zonal", "crane"}
+
+	hints := HintsFor("slate", someAnswers)
+	if len(hints) != len(someAnswers) {
+		t.Fatalf("got %d hints for %d answers", len(hints), len(someAnswers))
+	}
+	for i, answer := range someAnswers {
+		if want := getHint("slate", answer); hints[i] != want {
+			t.Errorf("hint %d against %s = %s, want %s", i, answer, hints[i].Digits(), want.Digits())
+		}
+	}
+
+	if hints := HintsFor("slate", nil); len(hints) != 0 {
+		t.Errorf("HintsFor no answers = %v", hints)
+	}
+
+	if hints := HintsFor("slates", someAnswers); hints != nil {
+		t.Errorf("HintsFor a 6 letter guess = %v, want nil", hints)
+	}
+	if hints := HintsFor("slate", []string{"crane", "cat"}); hints != nil {
+		t.Errorf("HintsFor a 3 letter answer = %v, want nil", hints)
+	}
+}
+
+func TestGuessesMapSwapWhileReading(t *testing.T) {
+	useFixture(t)
+
+	// a second copy of the same hints to swap back and forth with
+	other := NewSolver(guesses, answers)
+	if err := other.Build(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	guessesMapMu.RLock()
+	maps := []map[string]*GuessInfo{guessesMap, other.guessesMap}
+	guessesMapMu.RUnlock()
+
+	want := ExpectedRemaining("fmnst", allAnswers())
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				if got := ExpectedRemaining("fmnst", allAnswers()); got != want {
+					t.Errorf("ExpectedRemaining = %v during a swap, want %v", got, want)
+					return
+				}
+				if lookupGuessInfo("crane") == nil {
+					t.Error("crane went missing during a swap")
+					return
+				}
+			}
+		}()
+	}
+	for i := range 200 {
+		setGuessesMap(maps[i%2])
+	}
+	wg.Wait()
+}
+
+// TestUpdateCacheWhileReading is only meaningful with -race: lookups fill in
+// lazy bitvecs, which mustn't happen while UpdateCache is saving the map
+func TestUpdateCacheWhileReading(t *testing.T) {
+	useFixture(t)
+	allGuesses := guesses
+
+	SetWordLists(allGuesses[:2], answers)
+	if err := calculateHints(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	calculateBitvecs()
+	writeTestCache(t)
+
+	// every other guess is new, and its bitvecs are only built when read
+	setForTest(t, &LazyBitvecs, true)
+	SetWordLists(allGuesses, answers)
+	candidates := allAnswers()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				for _, guess := range allGuesses {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					ExpectedRemaining(guess, candidates)
+				}
+			}
+		}()
+	}
+
+	UpdateCache()
+	close(done)
+	wg.Wait()
+
+	if m := loadGuessesMap(); len(m) != len(allGuesses) {
+		t.Errorf("saved cache has %d guesses, want %d", len(m), len(allGuesses))
+	}
+}
+
+func TestWriteWordHintsOrder(t *testing.T) {
+	useFixture(t)
+
+	// fmnst has two hints with 4 answers and ten with 1
+	var first strings.Builder
+	writeWordHints(&first, "fmnst")
+	lines := strings.Split(strings.TrimSuffix(first.String(), "\n"), "\n")
+	var want []string
+	for _, last := range []int{1, 2} {
+		hint, err := hintFromDigits([]int{0, 0, 0, 0, last})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, fmt.Sprint(hint.ColoredWord("fmnst"), " 4"))
+	}
+	if len(lines) != 12 || !slices.Equal(lines[:2], want) {
+		t.Fatalf("got %d lines starting with %q, want 12 starting with %q", len(lines), lines[:2], want)
+	}
+
+	// map order changes from run to run, the output shouldn't
+	for range 20 {
+		var again strings.Builder
+		writeWordHints(&again, "fmnst")
+		if again.String() != first.String() {
+			t.Fatalf("output changed between runs:\n%s\nthen\n%s", first.String(), again.String())
+		}
+	}
+}
+
+func TestHintsForAnswer(t *testing.T) {
+	useFixture(t)
+
+	// crate is an answer so the cached hints are used, fmnst isn't so they're
+	// computed
+	for _, answer := range []string{"crate", "fmnst"} {
+		hints := HintsForAnswer(answer)
+		if len(hints) != len(guesses) {
+			t.Errorf("%v: got hints for %d guesses, want %d", answer, len(hints), len(guesses))
+		}
+		for _, guess := range guesses {
+			if want := getHint(guess, answer); hints[guess] != want {
+				t.Errorf("%v against %v = %s, want %s", guess, answer, hints[guess].Digits(), want.Digits())
+			}
+		}
+	}
+	for _, answer := range []string{"cat", "cranes"} {
+		if hints := HintsForAnswer(answer); hints != nil {
+			t.Errorf("HintsForAnswer(%q) = %v, want nil", answer, hints)
+		}
+	}
+}
+
+func TestLoadGuessesMapRebuildsForOtherCaseSensitivity(t *testing.T) {
+	useFixture(t)
+	setForTest(t, &CaseSensitive, true)
+	writeTestCache(t)
+
+	// folding case changes the hints for a list with upper case letters, so a
+	// cache from the other setting can't be trusted either way
+	CaseSensitive = false
+	if loaded := loadGuessesMap(); len(loaded) != 0 {
+		t.Errorf("case sensitive cache loaded %d guesses with case folding on", len(loaded))
+	}
+
+	writeTestCache(t)
+	if loaded := loadGuessesMap(); len(loaded) != len(guesses) {
+		t.Errorf("case folded cache loaded %d guesses, want %d", len(loaded), len(guesses))
+	}
+	CaseSensitive = true
+	if loaded := loadGuessesMap(); len(loaded) != 0 {
+		t.Errorf("case folded cache loaded %d guesses with case folding off", len(loaded))
+	}
+}
+
+func TestLoadGuessesMapRejectsWrongCounts(t *testing.T) {
+	useFixture(t)
+
+	bv := lookupBitvec("fmnst", "crate")
+	if !bv.VerifyCount() {
+		t.Fatalf("fresh bitvec %v fails VerifyCount", bv)
+	}
+	bv.Count++
+	if bv.VerifyCount() {
+		t.Fatalf("VerifyCount passed with Count %d for %v", bv.Count, bv)
+	}
+
+	writeTestCache(t)
+	loaded := loadGuessesMap()
+	if len(loaded) != 0 {
+		t.Fatalf("cache with a wrong count loaded %d guesses", len(loaded))
+	}
+
+	// like at startup, an empty map makes buildGuessesMap start over
+	setGuessesMap(loaded)
+	if err := buildGuessesMap(); err != nil {
+		t.Fatal(err)
+	}
+	if bv := lookupBitvec("fmnst", "crate"); !bv.VerifyCount() {
+		t.Errorf("rebuilt bitvec %v still has the wrong count", bv)
+	}
+}
+
+func TestShortGuessRejected(t *testing.T) {
+	useFixture(t)
+
+	if _, err := GetHint("cat", "crate"); err == nil || !strings.Contains(err.Error(), `"cat" has 3 letters, want 5`) {
+		t.Errorf("GetHint(cat, crate) error = %v", err)
+	}
+	if _, err := GetHint("crate", "cat"); err == nil {
+		t.Error("GetHint(crate, cat) didn't return an error")
+	}
+
+	g := NewGame()
+	if err := g.Apply("cat", 0); err == nil || g.RemainingCount() != len(answers) {
+		t.Errorf("Apply(cat) = %v and left %d candidates", err, g.RemainingCount())
+	}
+
+	if _, err := parseGuessArg("cat:000"); err == nil {
+		t.Error("parseGuessArg(cat:000) didn't return an error")
+	}
+}
+
+func TestLoadGuessesMapRejectsReorderedAnswers(t *testing.T) {
+	useFixture(t)
+	writeTestCache(t)
+
+	fixtureAnswers := answers
+	shuffled := slices.Clone(answers)
+	shuffled[0], shuffled[len(shuffled)-1] = shuffled[len(shuffled)-1], shuffled[0]
+
+	SetWordLists(guesses, shuffled)
+	if loaded := loadGuessesMap(); len(loaded) != 0 {
+		t.Fatalf("cache loaded %d guesses for a reordered answer list", len(loaded))
+	}
+
+	SetWordLists(guesses, fixtureAnswers)
+	if loaded := loadGuessesMap(); len(loaded) != len(guesses) {
+		t.Errorf("cache loaded %d guesses for the original order, want %d", len(loaded), len(guesses))
+	}
+}
+
+func TestAvgNumCandidatesParallel(t *testing.T) {
+	useFixture(t)
+
+	for _, guesses := range [][]string{{"fmnst"}, {"fmnst", "baker"}, {"crane", "tight", "steal"}} {
+		want := AvgNumCandidates(guesses[0], guesses[1:]...)
+		for _, workers := range []int{1, 3, 8, 100} {
+			if got := AvgNumCandidatesParallel(workers, guesses[0], guesses[1:]...); got != want {
+				t.Errorf("%v with %d workers = %v, want %v", guesses, workers, got, want)
+			}
+		}
+	}
+
+	// fmnst's buckets are 4, 4, 3, and nine 1s, with 2 or fewer counting as 1
+	if got, want := AvgNumCandidates("fmnst"), (4*4+4*4+3*3+9*1)/20.0; got != want {
+		t.Errorf("AvgNumCandidates(fmnst) = %v, want %v", got, want)
+	}
+
+	// the pair search calls this for every pair, so it mustn't start
+	// goroutines or allocate
+	AvgNumCandidates("fmnst", "baker")
+	if allocs := testing.AllocsPerRun(100, func() { AvgNumCandidates("fmnst", "baker") }); allocs != 0 {
+		t.Errorf("AvgNumCandidates made %v allocations", allocs)
+	}
+}
+
+func TestSelfHint(t *testing.T) {
+	useFixture(t)
+
+	for _, word := range slices.Concat(answers, guesses) {
+		if got := SelfHint(word); got != allGreen() {
+			t.Errorf("SelfHint(%v) = %v", word, got)
+		}
+	}
+
+	for _, word := range []string{"cat", "crane\n", ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SelfHint(%q) didn't panic", word)
+				}
+			}()
+			SelfHint(word)
+		}()
+	}
+}
+
+func TestUniqueLetterGuessesLargerAlphabet(t *testing.T) {
+	// German in Latin-1, one byte per letter: ä, ö, ü, and ß make 30
+	setForTest(t, &Alphabet, Alphabet+"\xe4\xf6\xfc\xdf")
+	useWordLists(t, "testdata/latin1.txt", "testdata/latin1.txt")
+
+	if got := letterIndex('\xdf'); got != 29 {
+		t.Errorf("letterIndex(ß) = %d, want 29", got)
+	}
+
+	got, letterSets := uniqueLetterGuesses()
+	want := []string{"b\xe4ren", "h\xf6ren", "stra\xdf", "crane", "m\xfcde\xdf", "steal"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("uniqueLetterGuesses() = %q, want %q", got, want)
+	}
+	for i, letters := range letterSets {
+		if letters.Size != 30 || letters.Count != WordLen {
+			t.Errorf("%q has letter set %v", got[i], letters)
+		}
+	}
+	if !letterSets[0].Get(26) || !letterSets[2].Get(29) {
+		t.Errorf("ä and ß aren't in the letter sets for %q and %q", got[0], got[2])
+	}
+}
+
+func TestMaxWorkersOne(t *testing.T) {
+	useFixture(t)
+
+	// calculateHints refills guessesMap with new entries, so keep the old ones
+	guessesMapMu.RLock()
+	wantHints := maps.Clone(guessesMap)
+	guessesMapMu.RUnlock()
+	wantRanked := RankAllGuesses(allAnswers())
+
+	setForTest(t, &MaxWorkers, 1)
+	peak := countWorkers(t)
+
+	if err := calculateHints(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	calculateBitvecs()
+	if err := findBestGuess(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, guess := range guesses {
+		got, want := lookupGuessInfo(guess), wantHints[guess]
+		if !maps.Equal(got.AnswerHints, want.AnswerHints) {
+			t.Errorf("%v's hints changed with MaxWorkers = 1", guess)
+		}
+		for hint, hintInfo := range want.HintsMap {
+			if !slices.Equal(got.hintBitvec(hint).Bytes, hintInfo.Bitvec.Bytes) {
+				t.Errorf("%v's bitvec for %v changed with MaxWorkers = 1", guess, hint.Digits())
+			}
+		}
+	}
+	if got := RankAllGuesses(allAnswers()); !slices.Equal(got, wantRanked) {
+		t.Errorf("RankAllGuesses changed with MaxWorkers = 1")
+	}
+	if got := peak(); got != 1 {
+		t.Errorf("%d workers ran at once with MaxWorkers = 1", got)
+	}
+}
+
+func TestHintFromSequence(t *testing.T) {
+	seen := make(map[Hint]bool)
+	var seq [WordLen]int
+	for {
+		hint := HintFromSequence(seq)
+		if hint.Sequence() != seq {
+			t.Errorf("HintFromSequence(%v).Sequence() = %v", seq, hint.Sequence())
+		}
+		if seen[hint] {
+			t.Errorf("HintFromSequence(%v) = %d, which another sequence also gave", seq, hint)
+		}
+		seen[hint] = true
+
+		// count up in base HintBase, last letter fastest
+		i := WordLen - 1
+		for i >= 0 && seq[i] == HintBase()-1 {
+			seq[i] = 0
+			i--
+		}
+		if i < 0 {
+			break
+		}
+		seq[i]++
+	}
+	if len(seen) != 243 || !seen[allGreen()] || !seen[0] {
+		t.Errorf("%d different hints, want 243 from 0 to allGreen()", len(seen))
+	}
+
+	for _, bad := range [][WordLen]int{{0, 0, 3, 0, 0}, {-1, 0, 0, 0, 0}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("HintFromSequence(%v) didn't panic", bad)
+				}
+			}()
+			HintFromSequence(bad)
+		}()
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/")
+
+// TestBestGuessPairGolden runs the whole pipeline and checks the best pair
+// against a golden file. By default it uses every 20th guess and every 5th
+// answer of the real lists, kept in testdata/sample_*.txt so it's quick. The
+// real lists in io/ take a couple of hours on one core, since every pair
+// from the full guess list is searched, so they only run with WORDLE_GOLDEN=1
+// set:
+//
+//	go test -run TestBestGuessPairGolden [-update]
+//	WORDLE_GOLDEN=1 go test -run TestBestGuessPairGolden -timeout 0 [-update]
+func TestBestGuessPairGolden(t *testing.T) {
+	tests := []struct {
+		name             string
+		guesses, answers string
+		golden           string
+		slow             bool
+	}{
+		{"sample", "testdata/sample_guesses.txt", "testdata/sample_answers.txt", "testdata/best_pair_sample.golden", false},
+		{"real", "io/guesses.txt", "io/answers.txt", "testdata/best_pair.golden", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.slow && os.Getenv("WORDLE_GOLDEN") == "" {
+				t.Skip("slow, set WORDLE_GOLDEN=1 to run it")
+			}
+			for _, path := range []string{tt.guesses, tt.answers} {
+				if _, err := os.Stat(path); err != nil {
+					t.Skip("needs the word lists:", err)
+				}
+			}
+			useWordLists(t, tt.guesses, tt.answers)
+
+			guess1, guess2, val, err := bestGuessPair(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := fmt.Sprintf("%v %v %.4f\n", guess1, guess2, val)
+
+			if *updateGolden {
+				if err := os.WriteFile(tt.golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(tt.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("best pair = %q, want %q from %v", got, want, tt.golden)
+			}
+		})
+	}
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	setForTest(b, &ProgressFunc, func(done, total int) {})
+
+	for b.Loop() {
+		if err := LoadWordLists("testdata/guesses.txt", "testdata/answers.txt"); err != nil {
+			b.Fatal(err)
+		}
+		if err := calculateHints(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+		calculateBitvecs()
+		if _, _, _, err := bestGuessPair(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLoadGuessesMapRebuildsForOtherWordLen(t *testing.T) {
+	useFixture(t)
+	t.Chdir(t.TempDir())
+
+	// everything else matches, as if only WordLen had changed between builds
+	file, err := os.Create("guesses_cache.gob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = gob.NewEncoder(file).Encode(guessesCache{
+		WordLen:       6,
+		NumAnswers:    len(answers),
+		AnswersHash:   answersHash(),
+		HintMode:      CurrentHintMode,
+		HintBase:      HintBase(),
+		CaseSensitive: CaseSensitive,
+		GuessesMap:    maps.Clone(guessesMap),
+	})
+	if err := errors.Join(err, file.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := loadGuessesMap()
+	if len(loaded) != 0 {
+		t.Fatalf("cache for 6 letter words loaded %d guesses", len(loaded))
+	}
+
+	setGuessesMap(loaded)
+	if err := buildGuessesMap(); err != nil {
+		t.Fatal(err)
+	}
+	for _, guess := range guesses {
+		for i, answer := range answers {
+			if !lookupBitvec(guess, answer).Get(i) {
+				t.Fatalf("rebuilt bitvec for %v doesn't have %v", guess, answer)
+			}
+		}
+	}
+}
+
+func TestWriteGuessesCacheFailureLeavesNoFile(t *testing.T) {
+	useFixture(t)
+
+	t.Run("rename fails", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Chdir(dir)
+
+		// the rename fails when the cache path is a directory
+		if err := os.Mkdir("guesses_cache.gob", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := saveGuessesMap(guessesMap); err == nil || !strings.Contains(err.Error(), "replacing cache file") {
+			t.Errorf("saveGuessesMap() = %v, want an error about replacing the cache file", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("failed saves left %v behind", entries)
+		}
+	})
+
+	t.Run("unwritable directory", func(t *testing.T) {
+		// a read-only directory doesn't stop root, but a file standing in for
+		// the directory stops everyone
+		dir := t.TempDir()
+		notDir := filepath.Join(dir, "cache")
+		if err := os.WriteFile(notDir, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		guessesMapMu.RLock()
+		err := writeGuessesCache(filepath.Join(notDir, "guesses_cache.gob"), guessesMap)
+		guessesMapMu.RUnlock()
+		if err == nil || !strings.Contains(err.Error(), "creating cache file") {
+			t.Fatalf("writeGuessesCache() = %v, want an error about creating the cache file", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("failed write left %v behind", entries)
+		}
+	})
+}
+
+func TestWriteGuessesCacheMode(t *testing.T) {
+	useFixture(t)
+	writeTestCache(t)
+
+	info, err := os.Stat("guesses_cache.gob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o644 {
+		t.Errorf("cache file mode = %v, want 0644", mode)
+	}
+}
+
+func TestHintBaseFour(t *testing.T) {
+	useFixture(t)
+
+	if err := SetHintBase(4); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetHintBase(3) })
+
+	// the cached hints were packed in base 3
+	guessesMapMu.RLock()
+	left := len(guessesMap)
+	guessesMapMu.RUnlock()
+	if left != 0 {
+		t.Errorf("guessesMap still has %d base 3 entries", left)
+	}
+
+	if allGreen() != HintFromSequence([WordLen]int{2, 2, 2, 2, 2}) {
+		t.Errorf("allGreen() = %v, not 22222 in base 4", allGreen().Digits())
+	}
+
+	// every hint survives Sequence, and String and back
+	seen := make(map[Hint]bool)
+	var seq [WordLen]int
+	for {
+		hint := HintFromSequence(seq)
+		if hint.Sequence() != seq {
+			t.Errorf("HintFromSequence(%v).Sequence() = %v", seq, hint.Sequence())
+		}
+		digits, err := hintDigits(hint.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed, err := hintFromDigits(digits); err != nil || parsed != hint {
+			t.Errorf("%v parsed back as %v, %v", hint, parsed, err)
+		}
+		seen[hint] = true
+
+		i := WordLen - 1
+		for i >= 0 && seq[i] == HintBase()-1 {
+			seq[i] = 0
+			i--
+		}
+		if i < 0 {
+			break
+		}
+		seq[i]++
+	}
+	if len(seen) != 1024 {
+		t.Errorf("%d different hints, want 4^5 = 1024", len(seen))
+	}
+
+	for _, base := range []int{2, 5, 10} {
+		if err := SetHintBase(base); err == nil {
+			t.Errorf("SetHintBase(%d) didn't return an error", base)
+		}
+		if HintBase() != 4 {
+			t.Fatalf("SetHintBase(%d) changed HintBase to %d", base, HintBase())
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MultiGame is Dordle/Quordle: several boards with independent answers that
+// all get the same guesses
+type MultiGame struct {
+	boards []*Game
+}
+
+// NewMultiGame starts a game with numBoards fresh boards
+func NewMultiGame(numBoards int) *MultiGame {
+	boards := make([]*Game, numBoards)
+	for i := range boards {
+		boards[i] = NewGame()
+	}
+
+	return &MultiGame{boards}
+}
+
+// Apply plays guess on every board, with hints[i] being the hint board i gave.
+// Boards that are already solved ignore it. A bad guess or the wrong number of
+// hints is an error and changes nothing.
+func (m *MultiGame) Apply(guess string, hints []Hint) error {
+	if err := checkWord(guess); err != nil {
+		return err
+	}
+	if len(hints) != len(m.boards) {
+		return fmt.Errorf("got %d hints for %d boards", len(hints), len(m.boards))
+	}
+
+	for i, board := range m.boards {
+		if !board.Solved() {
+			board.Apply(guess, hints[i])
+		}
+	}
+
+	return nil
+}
+
+// Solved reports whether every board has been solved
+func (m *MultiGame) Solved() bool {
+	for _, board := range m.boards {
+		if !board.Solved() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NextGuess picks the guess with the most total entropy across the unsolved
+// boards. A board that's down to one candidate gets that word guessed first,
+// since it can't give any more information.
+func (m *MultiGame) NextGuess() string {
+	var unsolved []*Game
+	for _, board := range m.boards {
+		if board.Solved() || board.candidates.Count == 0 {
+			continue
+		}
+		if board.candidates.Count == 1 {
+			return answers[board.candidates.FirstSet()]
+		}
+		unsolved = append(unsolved, board)
+	}
+
+	if len(unsolved) == 0 {
+		return ""
+	}
+
+	allowed := allowedGuesses()
+	if len(allowed) == 0 {
+		return ""
+	}
+	totals := make([]float64, len(allowed))
+
+	wg := sync.WaitGroup{}
+
+	for i, guess := range allowed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, board := range unsolved {
+				totals[i] += Entropy(guess, board.candidates)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	bestIdx := 0
+	for i := range allowed {
+		if totals[i] > totals[bestIdx] {
+			bestIdx = i
+		}
+	}
+
+	return allowed[bestIdx]
+}
@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+// mostEntropy returns the first guess with the most entropy over candidates
+func mostEntropy(candidates *Bitvec) string {
+	best := guesses[0]
+	for _, guess := range guesses {
+		if Entropy(guess, candidates) > Entropy(best, candidates) {
+			best = guess
+		}
+	}
+
+	return best
+}
+
+func TestMultiGameNextGuess(t *testing.T) {
+	useFixture(t)
+
+	board1 := fixtureBitvec(t, "light", "maker", "might", "night", "sight")
+	board2 := fixtureBitvec(t, "might", "slate", "stale", "zonal")
+	m := &MultiGame{[]*Game{{candidates: board1}, {candidates: board2}}}
+
+	total := func(guess string) float64 {
+		return Entropy(guess, board1) + Entropy(guess, board2)
+	}
+
+	// fmnst splits the first board perfectly but barely touches the second
+	if best := mostEntropy(board1); best != "fmnst" {
+		t.Fatalf("best guess for the first board alone = %v, want fmnst", best)
+	}
+
+	guess := m.NextGuess()
+	if guess == "fmnst" || total(guess) <= total("fmnst") {
+		t.Errorf("NextGuess() = %v with %.3f bits in total, want something beating fmnst's %.3f",
+			guess, total(guess), total("fmnst"))
+	}
+	for _, other := range guesses {
+		if total(other) > total(guess) {
+			t.Errorf("%v gives %.3f bits in total, more than %v's %.3f", other, total(other), guess, total(guess))
+		}
+	}
+}
+
+func TestMultiGameSkipsSolvedBoards(t *testing.T) {
+	useFixture(t)
+
+	m := NewMultiGame(2)
+	if err := m.Apply("tonal", []Hint{allGreen(), getHint("tonal", "light")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the second board is left, so it's played like a single game
+	want := mostEntropy(m.boards[1].candidates)
+	if m.boards[1].candidates.Count == 1 {
+		want = "light"
+	}
+	if guess := m.NextGuess(); guess != want {
+		t.Errorf("NextGuess() = %v, want %v", guess, want)
+	}
+}
+
+func TestMultiGameApplyWrongHintCount(t *testing.T) {
+	useFixture(t)
+
+	m := NewMultiGame(2)
+	if err := m.Apply("tonal", []Hint{allGreen()}); err == nil {
+		t.Error("Apply() with 1 hint for 2 boards didn't return an error")
+	}
+	for i, board := range m.boards {
+		if len(board.history) != 0 {
+			t.Errorf("board %d was played anyway", i)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"slices"
+)
+
+// optimalProbes is how many non-candidate guesses OptimalGuess tries at each
+// step, picked by highest Entropy
+const optimalProbes = 10
+
+// OptimalGuess searches the game tree under candidates for the guess with the
+// lowest expected number of guesses to finish, counting the guess itself.
+// Only the candidates plus the best few probes by Entropy are tried at each
+// step, and past maxDepth guesses the rest is estimated as guessing the
+// remaining candidates one at a time. This is exponential, so keep candidates
+// small.
+func OptimalGuess(candidates *Bitvec, maxDepth int) (string, float64) {
+	if candidates.Count == 0 {
+		return "", 0
+	}
+
+	type memoKey struct {
+		candidates uint64
+		depth      int
+	}
+	type memoVal struct {
+		candidates *Bitvec
+		guess      string
+		expected   float64
+	}
+	// the hash only picks the bucket, since two different candidate sets can
+	// hash the same; the sets in it are compared in full
+	memo := make(map[memoKey][]memoVal)
+
+	var search func(candidates *Bitvec, depth int) (string, float64)
+	search = func(candidates *Bitvec, depth int) (string, float64) {
+		first := answers[candidates.FirstSet()]
+		n := float64(candidates.Count)
+
+		if candidates.Count == 1 {
+			return first, 1
+		}
+		if depth == 0 {
+			return first, (n + 1) / 2
+		}
+
+		key := memoKey{candidates.Hash(), depth}
+		for _, val := range memo[key] {
+			// same count and a subset means the same set
+			if val.candidates.Count == candidates.Count && val.candidates.IsSubsetOf(candidates) {
+				return val.guess, val.expected
+			}
+		}
+
+		bestGuess, bestExpected := first, (n+1)/2
+		for _, guess := range optimalGuessOptions(candidates) {
+			guessInfo := lookupGuessInfo(guess)
+			expected := 1.0
+			useful := true
+
+			for hint := range bucketCounts(guess, candidates) {
+				if hint == allGreen() {
+					continue
+				}
+				bucket := guessInfo.hintBitvec(hint).And(candidates)
+				if bucket.Count == candidates.Count {
+					// learns nothing, so it can't beat the fallback
+					useful = false
+					break
+				}
+				_, bucketExpected := search(bucket, depth-1)
+				expected += float64(bucket.Count) / n * bucketExpected
+			}
+
+			if useful && expected < bestExpected {
+				bestGuess, bestExpected = guess, expected
+			}
+		}
+
+		memo[key] = append(memo[key], memoVal{candidates, bestGuess, bestExpected})
+		return bestGuess, bestExpected
+	}
+
+	return search(candidates, maxDepth)
+}
+
+// optimalGuessOptions returns the guesses OptimalGuess considers: every
+// candidate, then the optimalProbes other guesses with the highest Entropy
+func optimalGuessOptions(candidates *Bitvec) []string {
+	var options []string
+	for i := candidates.FirstSet(); i != -1; i = candidates.NextSet(i) {
+		options = append(options, answers[i])
+	}
+
+	type probe struct {
+		guess   string
+		entropy float64
+	}
+	var probes []probe
+	for _, guess := range allowedGuesses() {
+		if !slices.Contains(options, guess) {
+			probes = append(probes, probe{guess, Entropy(guess, candidates)})
+		}
+	}
+
+	// stable so equal entropies keep list order
+	slices.SortStableFunc(probes, func(a, b probe) int {
+		if a.entropy > b.entropy {
+			return -1
+		}
+		if a.entropy < b.entropy {
+			return 1
+		}
+		return 0
+	})
+
+	for _, p := range probes[:min(optimalProbes, len(probes))] {
+		options = append(options, p.guess)
+	}
+
+	return options
+}
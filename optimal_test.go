@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// exhaustiveExpected is the lowest expected number of guesses to find the
+// answer among candidates, trying every guess at every step
+func exhaustiveExpected(candidates []string) float64 {
+	if len(candidates) == 1 {
+		return 1
+	}
+
+	best := math.Inf(1)
+	for _, guess := range guesses {
+		best = min(best, exhaustiveExpectedAfter(guess, candidates))
+	}
+
+	return best
+}
+
+// exhaustiveExpectedAfter is exhaustiveExpected when guess is played first, or
+// +Inf if guess doesn't narrow down candidates
+func exhaustiveExpectedAfter(guess string, candidates []string) float64 {
+	buckets := make(map[Hint][]string)
+	for _, answer := range candidates {
+		hint := getHint(guess, answer)
+		buckets[hint] = append(buckets[hint], answer)
+	}
+	if len(buckets) == 1 && buckets[allGreen()] == nil {
+		return math.Inf(1)
+	}
+
+	expected := 1.0
+	for hint, bucket := range buckets {
+		if hint != allGreen() {
+			expected += float64(len(bucket)) / float64(len(candidates)) * exhaustiveExpected(bucket)
+		}
+	}
+
+	return expected
+}
+
+func TestOptimalGuess(t *testing.T) {
+	useFixture(t)
+
+	// none of the best guesses here are candidates
+	candidates := []string{"fight", "light", "tight", "wight", "sight"}
+	guess, expected := OptimalGuess(fixtureBitvec(t, candidates...), len(candidates))
+
+	want := exhaustiveExpected(candidates)
+	if math.Abs(expected-want) > 1e-9 {
+		t.Errorf("OptimalGuess expects %v guesses, want %v", expected, want)
+	}
+	if got := exhaustiveExpectedAfter(guess, candidates); math.Abs(got-want) > 1e-9 {
+		t.Errorf("OptimalGuess picked %s, which takes %v guesses on average, want %v", guess, got, want)
+	}
+}
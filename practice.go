@@ -0,0 +1,91 @@
+package main
+
+import "math/rand/v2"
+
+// PracticeGame hosts a game: it picks a secret answer and hints guesses
+// against it until the answer is found or MaxGuesses run out
+type PracticeGame struct {
+	answer  string
+	guesses int
+	solved  bool
+}
+
+// NewPracticeGame picks a random answer. The same seed always picks the same
+// answer. It's nil if there are no answers to pick from.
+func NewPracticeGame(seed int64) *PracticeGame {
+	if len(answers) == 0 {
+		return nil
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
+	return &PracticeGame{answer: answers[rng.IntN(len(answers))]}
+}
+
+// NewWeightedPracticeGame picks the answer with probability proportional to
+// its weight, so common words come up more like they do in the real game.
+// Answers missing from weights can't be picked, and if no answer has a
+// positive weight it picks uniformly like NewPracticeGame. The same seed and
+// weights always pick the same answer.
+func NewWeightedPracticeGame(seed int64, weights map[string]float64) *PracticeGame {
+	var total float64
+	for _, answer := range answers {
+		total += max(weights[answer], 0)
+	}
+	if total == 0 {
+		return NewPracticeGame(seed)
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
+	target := rng.Float64() * total
+
+	// walk the answers in list order rather than the map so the draw is
+	// reproducible
+	picked := ""
+	for _, answer := range answers {
+		weight := max(weights[answer], 0)
+		if weight == 0 {
+			continue
+		}
+		picked = answer
+		if target < weight {
+			break
+		}
+		target -= weight
+	}
+
+	return &PracticeGame{answer: picked}
+}
+
+// Guess returns the hint for word and whether it was the answer. Once the game
+// is over, further guesses aren't counted and get a zero hint and false, and
+// so do words of the wrong length.
+func (p *PracticeGame) Guess(word string) (Hint, bool) {
+	if p.GameOver() || checkWord(word) != nil {
+		return 0, false
+	}
+
+	p.guesses++
+	hint := getHint(word, p.answer)
+	p.solved = hint == allGreen()
+	return hint, p.solved
+}
+
+// GameOver reports whether the answer was found or the guesses ran out
+func (p *PracticeGame) GameOver() bool {
+	return p.solved || p.guesses >= MaxGuesses
+}
+
+// Won reports whether the answer was found
+func (p *PracticeGame) Won() bool {
+	return p.solved
+}
+
+// NumGuesses is how many guesses have been counted so far
+func (p *PracticeGame) NumGuesses() int {
+	return p.guesses
+}
+
+// Reveal returns the secret answer
+func (p *PracticeGame) Reveal() string {
+	return p.answer
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestPracticeGame(t *testing.T) {
+	useFixture(t)
+
+	p := NewPracticeGame(7)
+	if other := NewPracticeGame(7); other.Reveal() != p.Reveal() {
+		t.Fatalf("seed 7 picked %v and then %v", p.Reveal(), other.Reveal())
+	}
+
+	// play it the way the solver would, only going by the hints
+	g := NewGame()
+	for !p.GameOver() {
+		guess, err := g.NextGuess()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hint, solved := p.Guess(guess)
+		if solved != (guess == p.Reveal()) {
+			t.Fatalf("guessing %v against %v said solved = %v", guess, p.Reveal(), solved)
+		}
+		g.Apply(guess, hint)
+	}
+	if !p.Won() || !g.Solved() {
+		t.Errorf("lost after %d guesses with the answer %v", p.NumGuesses(), p.Reveal())
+	}
+	if hint, solved := p.Guess(p.Reveal()); hint != 0 || solved || p.NumGuesses() > MaxGuesses {
+		t.Errorf("a guess after winning counted")
+	}
+}
+
+func TestPracticeGameLoses(t *testing.T) {
+	useFixture(t)
+
+	p := NewPracticeGame(7)
+	wrong := "fmnst"
+	if p.Reveal() == wrong {
+		wrong = "roate"
+	}
+
+	if _, solved := p.Guess("four"); solved || p.NumGuesses() != 0 {
+		t.Errorf("a 4 letter guess counted")
+	}
+	for i := range MaxGuesses {
+		if p.GameOver() {
+			t.Fatalf("game over after %d guesses", i)
+		}
+		p.Guess(wrong)
+	}
+
+	if !p.GameOver() || p.Won() {
+		t.Errorf("after %d wrong guesses GameOver = %v and Won = %v", MaxGuesses, p.GameOver(), p.Won())
+	}
+	if _, solved := p.Guess(p.Reveal()); solved || p.NumGuesses() != MaxGuesses {
+		t.Errorf("the right answer still counted after the game was over")
+	}
+}
+
+func TestNewWeightedPracticeGame(t *testing.T) {
+	useFixture(t)
+
+	weights := map[string]float64{"crane": 1, "tonal": 1000, "fmnst": 50, "zonal": -5}
+	picks := map[string]int{}
+	for seed := range int64(200) {
+		p := NewWeightedPracticeGame(seed, weights)
+		if again := NewWeightedPracticeGame(seed, weights); again.Reveal() != p.Reveal() {
+			t.Fatalf("seed %d picked %v and then %v", seed, p.Reveal(), again.Reveal())
+		}
+
+		// crane comes first in the list, so it's picked when the draw lands in
+		// its first 1 of the 1001 total; fmnst isn't an answer and zonal's
+		// negative weight counts as 0
+		draw := rand.New(rand.NewPCG(uint64(seed), 0)).Float64() * 1001
+		want := "tonal"
+		if draw < 1 {
+			want = "crane"
+		}
+		if p.Reveal() != want {
+			t.Errorf("seed %d picked %v, want %v", seed, p.Reveal(), want)
+		}
+		picks[p.Reveal()]++
+	}
+	if picks["tonal"] < 190 {
+		t.Errorf("tonal was picked %d times out of 200", picks["tonal"])
+	}
+
+	// with no usable weights it's the same as NewPracticeGame
+	zero := map[string]float64{"crane": 0, "fmnst": 3}
+	if got, want := NewWeightedPracticeGame(7, zero).Reveal(), NewPracticeGame(7).Reveal(); got != want {
+		t.Errorf("no positive weights picked %v, want %v like NewPracticeGame", got, want)
+	}
+}
+
+func TestPracticeGameNoAnswers(t *testing.T) {
+	useFixture(t)
+	SetWordLists(guesses, nil)
+
+	if p := NewPracticeGame(7); p != nil {
+		t.Errorf("NewPracticeGame() with no answers = %+v, want nil", p)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Profile turns on timing of the hot spots listed in profileStats, for
+// finding out where the build spends its time without setting up pprof.
+// Timing every call slows them down, so leave it off otherwise. Setting the
+// profileEnv environment variable turns it on for a run, and main prints the
+// profile when it's done.
+var Profile = false
+
+// profileEnv is the environment variable that turns on Profile
+const profileEnv = "WORDLE_PROFILE"
+
+// profileStat is the number of calls to one function and the total time
+// spent in them
+type profileStat struct {
+	calls atomic.Int64
+	nanos atomic.Int64
+}
+
+// profileStats holds the timed functions. The keys never change, so it's
+// safe to read from any goroutine.
+var profileStats = map[string]*profileStat{
+	"getHint":          {},
+	"Bitvec.And":       {},
+	"Bitvec.AndInto":   {},
+	"AvgNumCandidates": {},
+}
+
+// recordProfile adds the time since start to name's total. Call it as
+// `defer recordProfile(name, time.Now())` when Profile is on.
+func recordProfile(name string, start time.Time) {
+	stat := profileStats[name]
+	stat.calls.Add(1)
+	stat.nanos.Add(int64(time.Since(start)))
+}
+
+// ProfileTimes returns the total time spent in each timed function so far
+func ProfileTimes() map[string]time.Duration {
+	times := make(map[string]time.Duration, len(profileStats))
+	for name, stat := range profileStats {
+		times[name] = time.Duration(stat.nanos.Load())
+	}
+
+	return times
+}
+
+// PrintProfile prints the calls and time spent in each timed function, most
+// time first, to stderr so it stays out of a command's output
+func PrintProfile() {
+	names := make([]string, 0, len(profileStats))
+	for name := range profileStats {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return profileStats[names[i]].nanos.Load() > profileStats[names[j]].nanos.Load()
+	})
+
+	for _, name := range names {
+		stat := profileStats[name]
+		calls, nanos := stat.calls.Load(), stat.nanos.Load()
+
+		var perCall time.Duration
+		if calls > 0 {
+			perCall = time.Duration(nanos / calls)
+		}
+		fmt.Fprintf(os.Stderr, "%-16s %10d calls %12v total %10v per call\n", name, calls, time.Duration(nanos), perCall)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+// profileCalls returns how many calls each timed function has had so far
+func profileCalls() map[string]int64 {
+	calls := make(map[string]int64, len(profileStats))
+	for name, stat := range profileStats {
+		calls[name] = stat.calls.Load()
+	}
+
+	return calls
+}
+
+func TestProfile(t *testing.T) {
+	useFixture(t)
+
+	run := func() {
+		getHint("crane", "crate")
+		allAnswers().And(lookupBitvec("fmnst", "crate"))
+		AvgNumCandidates("fmnst", "tight")
+	}
+
+	before := profileCalls()
+	run()
+	if after := profileCalls(); !maps.Equal(after, before) {
+		t.Errorf("calls were counted with Profile off: %v, was %v", after, before)
+	}
+
+	setForTest(t, &Profile, true)
+	run()
+	after := profileCalls()
+	for name := range profileStats {
+		if after[name] <= before[name] {
+			t.Errorf("%v wasn't timed", name)
+		}
+	}
+	if len(ProfileTimes()) != len(profileStats) {
+		t.Errorf("ProfileTimes() = %v, want a time for each of %d functions", ProfileTimes(), len(profileStats))
+	}
+}
+
+func TestMainPrintsProfile(t *testing.T) {
+	// printed even when the command fails, and kept out of stdout
+	stdout, stderr, err := runMain(t, []string{profileEnv + "=1"}, "suggest", "zzzzz:00000")
+	if err == nil {
+		t.Fatal("suggesting after an unknown guess didn't fail")
+	}
+	if !strings.Contains(stderr, "getHint") {
+		t.Errorf("no profile on stderr:\n%s", stderr)
+	}
+	if strings.Contains(stdout, "getHint") {
+		t.Errorf("the profile went to stdout:\n%s", stdout)
+	}
+}
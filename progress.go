@@ -0,0 +1,48 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// ProgressFunc, if set, gets called with progress updates from the long
+// running computations instead of drawing a progress bar. Calls are never
+// concurrent, and done only goes up.
+var ProgressFunc func(done, total int)
+
+// progress reports to ProgressFunc if it's set, or to a progress bar if not
+type progress struct {
+	bar   *progressbar.ProgressBar
+	mu    sync.Mutex
+	done  int
+	total int
+}
+
+func newProgress(total int) *progress {
+	if ProgressFunc != nil {
+		return &progress{total: total}
+	}
+
+	return &progress{bar: progressbar.Default(int64(total)), total: total}
+}
+
+func (p *progress) Add(n int) {
+	if p.bar != nil {
+		p.bar.Add(n)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	ProgressFunc(p.done, p.total)
+}
+
+// Describe sets the progress bar's description. ProgressFunc has nowhere to
+// show it, so it's dropped in that case.
+func (p *progress) Describe(description string) {
+	if p.bar != nil {
+		p.bar.Describe(description)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProgressFunc(t *testing.T) {
+	useFixture(t)
+
+	type call struct{ done, total int }
+	var calls []call
+	setForTest(t, &ProgressFunc, func(done, total int) {
+		calls = append(calls, call{done, total})
+	})
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"calculateHints", func() error { return calculateHints(context.Background()) }},
+		{"calculateBitvecs", func() error { calculateBitvecs(); return nil }},
+		{"findBestGuess", func() error { return findBestGuess(context.Background()) }},
+	}
+
+	for _, step := range steps {
+		calls = nil
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		if len(calls) == 0 {
+			t.Errorf("%s never called ProgressFunc", step.name)
+			continue
+		}
+		for i, c := range calls {
+			if c.total != calls[0].total {
+				t.Errorf("%s: total changed from %d to %d", step.name, calls[0].total, c.total)
+			}
+			if i > 0 && c.done <= calls[i-1].done {
+				t.Errorf("%s: done went from %d to %d", step.name, calls[i-1].done, c.done)
+			}
+		}
+		if last := calls[len(calls)-1]; last.done != last.total {
+			t.Errorf("%s: finished at %d of %d", step.name, last.done, last.total)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import "sync"
+
+// Scorer rates how good guess is against candidates. Lower is better.
+type Scorer interface {
+	Score(guess string, candidates *Bitvec) float64
+}
+
+// AvgCandidatesScorer scores guesses by ExpectedRemaining
+type AvgCandidatesScorer struct{}
+
+func (AvgCandidatesScorer) Score(guess string, candidates *Bitvec) float64 {
+	return ExpectedRemaining(guess, candidates)
+}
+
+// EntropyScorer scores guesses by Entropy, negated so more information is
+// lower
+type EntropyScorer struct{}
+
+func (EntropyScorer) Score(guess string, candidates *Bitvec) float64 {
+	return -Entropy(guess, candidates)
+}
+
+// MinimaxScorer scores guesses by WorstCaseBucket
+type MinimaxScorer struct{}
+
+func (MinimaxScorer) Score(guess string, candidates *Bitvec) float64 {
+	return float64(WorstCaseBucket(guess, candidates))
+}
+
+// BestGuessWithin picks the allowed guess scorer rates lowest against
+// candidates. Ties go to list order. Returns "" if there are no candidates
+// or no allowed guesses.
+func BestGuessWithin(candidates *Bitvec, scorer Scorer) string {
+	if candidates.Count == 0 {
+		return ""
+	}
+
+	allowed := allowedGuesses()
+	if len(allowed) == 0 {
+		return ""
+	}
+	scores := make([]float64, len(allowed))
+
+	wg := sync.WaitGroup{}
+
+	for i, guess := range allowed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scores[i] = scorer.Score(guess, candidates)
+		}()
+	}
+
+	wg.Wait()
+
+	bestIdx := 0
+	for i := range allowed {
+		if scores[i] < scores[bestIdx] {
+			bestIdx = i
+		}
+	}
+
+	return allowed[bestIdx]
+}
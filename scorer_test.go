@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+// letterScorer is a Scorer plugged in from outside: it prefers guesses with
+// more copies of one letter, and records every guess it's asked about
+type letterScorer struct {
+	letter byte
+
+	mu     sync.Mutex
+	scored map[string]int
+}
+
+func (s *letterScorer) Score(guess string, candidates *Bitvec) float64 {
+	s.mu.Lock()
+	s.scored[guess]++
+	s.mu.Unlock()
+
+	n := 0
+	for i := range len(guess) {
+		if guess[i] == s.letter {
+			n++
+		}
+	}
+	return float64(-n)
+}
+
+func TestBestGuessWithin(t *testing.T) {
+	useFixture(t)
+	candidates := allAnswers()
+
+	for _, scorer := range []Scorer{AvgCandidatesScorer{}, EntropyScorer{}, MinimaxScorer{}} {
+		got := BestGuessWithin(candidates, scorer)
+		best := guesses[0]
+		for _, guess := range guesses {
+			if scorer.Score(guess, candidates) < scorer.Score(best, candidates) {
+				best = guess
+			}
+		}
+		if got != best {
+			t.Errorf("BestGuessWithin with %T = %v, want %v", scorer, got, best)
+		}
+	}
+
+	// tight is the only guess with two t's
+	scorer := &letterScorer{letter: 't', scored: make(map[string]int)}
+	if got := BestGuessWithin(candidates, scorer); got != "tight" {
+		t.Errorf("BestGuessWithin with a custom scorer = %v, want tight", got)
+	}
+	// every a is on its own, so the first guess with one wins
+	if got := BestGuessWithin(candidates, &letterScorer{letter: 'a', scored: make(map[string]int)}); got != "baker" {
+		t.Errorf("BestGuessWithin with a tie = %v, want baker", got)
+	}
+	if len(scorer.scored) != len(guesses) {
+		t.Errorf("scored %d guesses, want all %d", len(scorer.scored), len(guesses))
+	}
+	for guess, n := range scorer.scored {
+		if n != 1 {
+			t.Errorf("%v was scored %d times", guess, n)
+		}
+	}
+
+	if got := BestGuessWithin(NewBitvec(len(answers)), scorer); got != "" {
+		t.Errorf("BestGuessWithin with no candidates = %v, want \"\"", got)
+	}
+}
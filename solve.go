@@ -0,0 +1,349 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxGuesses is how many guesses a game gets before it counts as a failure.
+// Wordle allows 6; variants and practice modes can change it.
+var MaxGuesses = 6
+
+// SolveStep is one guess in a simulated game along with why it was played
+type SolveStep struct {
+	Guess         string
+	Hint          Hint
+	Remaining     int
+	ChosenBecause string
+}
+
+// SolveVerbose plays a game against answer, recording every guess, the hint it
+// got back, how many candidates were left afterwards, and why it was chosen.
+// It gives up after MaxGuesses, or once there's no allowed guess to play, so
+// the game was lost if the last step's guess isn't answer. Returns nil if
+// answer isn't in the answer list.
+func SolveVerbose(answer string) []SolveStep {
+	if !slices.Contains(answers, answer) {
+		return nil
+	}
+
+	candidates := allAnswers()
+	guess, reason := chooseGuess(candidates)
+	return solveFrom(answer, candidates, guess, reason, MaxGuesses)
+}
+
+// SimulateAll plays SolveVerbose's strategy against every answer. Returns how
+// many games were won in each number of guesses, and the answers that weren't
+// found within MaxGuesses.
+func SimulateAll() (map[int]int, []string) {
+	// the opener is the same for every answer, so only pick it once
+	opener, reason := chooseGuess(allAnswers())
+
+	numGuesses := make(map[int]int)
+	var failures []string
+
+	for i, steps := range playAll(opener, reason, MaxGuesses) {
+		if won(steps, answers[i]) {
+			numGuesses[len(steps)]++
+		} else {
+			failures = append(failures, answers[i])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Done, %v failures\n", len(failures))
+	return numGuesses, failures
+}
+
+// HardestAnswers plays every answer starting from opener and returns the topN
+// that took the most guesses, most first. Games that weren't won within
+// MaxGuesses count as MaxGuesses+1. It's nil for a negative topN or an opener
+// checkOpener rejects.
+func HardestAnswers(opener string, topN int) []struct {
+	Word    string
+	Guesses int
+} {
+	if topN < 0 || checkOpener(opener) != nil {
+		return nil
+	}
+
+	hardest := make([]struct {
+		Word    string
+		Guesses int
+	}, len(answers))
+
+	for i, steps := range playAll(opener, "opener", MaxGuesses) {
+		hardest[i].Word = answers[i]
+		hardest[i].Guesses = len(steps)
+		if !won(steps, answers[i]) {
+			hardest[i].Guesses = MaxGuesses + 1
+		}
+	}
+
+	// stable so ties stay in answer list order
+	sort.SliceStable(hardest, func(i, j int) bool {
+		return hardest[i].Guesses > hardest[j].Guesses
+	})
+
+	return hardest[:min(topN, len(hardest))]
+}
+
+// ExportSolvePaths plays every answer starting from opener and writes a CSV
+// row for each, in answer list order: the answer, how many guesses it took,
+// and the guesses separated by spaces. Answers that weren't found within
+// MaxGuesses get FAIL for the count.
+func ExportSolvePaths(opener string, w io.Writer) error {
+	if err := checkOpener(opener); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"answer", "guesses", "path"})
+
+	for i, steps := range playAll(opener, "opener", MaxGuesses) {
+		path := make([]string, len(steps))
+		for j, step := range steps {
+			path[j] = step.Guess
+		}
+
+		count := strconv.Itoa(len(steps))
+		if !won(steps, answers[i]) {
+			count = "FAIL"
+		}
+
+		writer.Write([]string{answers[i], count, strings.Join(path, " ")})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// playAll plays a game against every answer starting with opener, returning
+// the steps for each answer in answer list order. An opener of "", from
+// chooseGuess finding no allowed guess, loses every game without a step.
+func playAll(opener, reason string, maxGuesses int) [][]SolveStep {
+	if opener == "" {
+		return make([][]SolveStep, len(answers))
+	}
+
+	fmt.Fprintf(os.Stderr, "Simulating %v games with %v guesses each\n", len(answers), maxGuesses)
+
+	bar := newProgress(len(answers))
+
+	candidates := allAnswers()
+	games := make([][]SolveStep, len(answers))
+
+	wg := sync.WaitGroup{}
+	limit := newWorkerLimit()
+
+	for i, answer := range answers {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-limit }()
+			defer trackWorker()()
+			games[i] = solveFrom(answer, candidates, opener, reason, maxGuesses)
+			bar.Add(1)
+		}()
+	}
+
+	wg.Wait()
+
+	return games
+}
+
+// UnresolvableWithin lists the answers, in answer list order, that the solver
+// doesn't find within maxGuesses when it starts with opener. It's nil for an
+// opener checkOpener rejects.
+func UnresolvableWithin(opener string, maxGuesses int) []string {
+	if checkOpener(opener) != nil {
+		return nil
+	}
+
+	var failures []string
+	for i, steps := range playAll(opener, "opener", maxGuesses) {
+		if !won(steps, answers[i]) {
+			failures = append(failures, answers[i])
+		}
+	}
+
+	return failures
+}
+
+// checkOpener returns an error if opener isn't one of allowedGuesses, so a
+// report never starts with a word the solver couldn't have played itself
+func checkOpener(opener string) error {
+	if lookupGuessInfo(opener) == nil {
+		return fmt.Errorf("%q is not a known guess", opener)
+	}
+	if !slices.Contains(allowedGuesses(), opener) {
+		return fmt.Errorf("%q is not an allowed guess", opener)
+	}
+
+	return nil
+}
+
+// allAnswers returns a bitvec with every answer set
+func allAnswers() *Bitvec {
+	s := defaultSolver()
+	return s.allAnswers()
+}
+
+// chooseGuess picks the next guess for candidates and explains why. The guess
+// is "" if there are no candidates or no allowed guess, e.g. when no guess has
+// every one of RequiredLetters.
+func chooseGuess(candidates *Bitvec) (string, string) {
+	s := defaultSolver()
+	return s.chooseGuess(candidates)
+}
+
+// solveFrom plays out a game against answer starting with the given guess,
+// giving up after maxGuesses or when chooseGuess has nothing to play
+func solveFrom(answer string, candidates *Bitvec, guess, reason string, maxGuesses int) []SolveStep {
+	var steps []SolveStep
+
+	for {
+		if guess == "" {
+			return steps
+		}
+
+		candidates = candidates.And(lookupBitvec(guess, answer))
+
+		steps = append(steps, SolveStep{
+			Guess:         guess,
+			Hint:          lookupGuessInfo(guess).AnswerHints[answer],
+			Remaining:     candidates.Count,
+			ChosenBecause: reason,
+		})
+
+		if guess == answer || len(steps) >= maxGuesses {
+			return steps
+		}
+
+		guess, reason = chooseGuess(candidates)
+	}
+}
+
+// won reports whether a game's steps end in guessing answer
+func won(steps []SolveStep, answer string) bool {
+	return len(steps) > 0 && steps[len(steps)-1].Guess == answer
+}
+
+// SecondGuessTable maps each hint opener can get to the guess the solver would
+// play next, i.e. a cheat sheet for the second guess. Hints no answer gives are
+// left out, and the table is nil if opener isn't a known guess.
+func SecondGuessTable(opener string) map[Hint]string {
+	guessInfo := lookupGuessInfo(opener)
+	if guessInfo == nil {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Finding second guesses for %v hints after %v\n", len(guessInfo.HintsMap), opener)
+	bar := newProgress(len(guessInfo.HintsMap))
+
+	table := make(map[Hint]string)
+	for hint := range guessInfo.HintsMap {
+		if bitvec := guessInfo.hintBitvec(hint); bitvec.Count > 0 {
+			table[hint], _ = chooseGuess(bitvec)
+		}
+		bar.Add(1)
+	}
+
+	return table
+}
+
+// BestOpenerByExpectedGuesses finds the opener with the lowest
+// expectedGuessesDepth2. This plays out two full guesses for every opener,
+// so it's very slow on the full lists.
+func BestOpenerByExpectedGuesses() (string, float64) {
+	allowed := allowedGuesses()
+	if len(allowed) == 0 {
+		return "", 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Finding best opener by expected guesses out of %v\n", len(allowed))
+	bar := newProgress(len(allowed))
+
+	candidates := allAnswers()
+	expected := make([]float64, len(allowed))
+
+	wg := sync.WaitGroup{}
+	limit := newWorkerLimit()
+
+	for i, opener := range allowed {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-limit }()
+			defer trackWorker()()
+			expected[i] = expectedGuessesDepth2(opener, candidates)
+			bar.Add(1)
+		}()
+	}
+
+	wg.Wait()
+
+	bestIdx := 0
+	for i := range allowed {
+		if expected[i] < expected[bestIdx] {
+			bestIdx = i
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Done, best opener: %v (%.3f)\n", allowed[bestIdx], expected[bestIdx])
+	return allowed[bestIdx], expected[bestIdx]
+}
+
+// BestOpenerForSubset is the opener with the lowest ExpectedRemaining when the
+// answer is known to be one of candidates, e.g. every answer ending in -ound.
+// Ties go to list order. Returns "" if there are no candidates.
+func BestOpenerForSubset(candidates *Bitvec) string {
+	if candidates.Count == 0 {
+		return ""
+	}
+
+	ranked := RankAllGuesses(candidates)
+	if len(ranked) == 0 {
+		return ""
+	}
+
+	return ranked[0].Guess
+}
+
+// expectedGuessesDepth2 estimates the expected number of guesses to solve
+// candidates starting with opener, with the second guess picked like
+// SecondGuessTable does. Whatever is left after that is assumed to be guessed
+// one candidate at a time.
+func expectedGuessesDepth2(opener string, candidates *Bitvec) float64 {
+	// total guesses after the opener over every candidate, kept as an int so
+	// the result doesn't depend on what order the buckets are visited in
+	total := 0
+
+	openerInfo := lookupGuessInfo(opener)
+	for hint := range bucketCounts(opener, candidates) {
+		if hint == allGreen() {
+			continue
+		}
+
+		bucket := openerInfo.hintBitvec(hint).And(candidates)
+		second, _ := chooseGuess(bucket)
+
+		total += bucket.Count
+		for secondHint, count := range bucketCounts(second, bucket) {
+			// guessing count candidates one by one takes 1+2+...+count
+			if secondHint != allGreen() {
+				total += count * (count + 1) / 2
+			}
+		}
+	}
+
+	return 1 + float64(total)/float64(candidates.Count)
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/csv"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSolveVerbose(t *testing.T) {
+	useFixture(t)
+
+	steps := SolveVerbose("light")
+	if len(steps) != 3 {
+		t.Fatalf("took %d steps, want 3: %+v", len(steps), steps)
+	}
+
+	for i, step := range steps {
+		if step.ChosenBecause == "" {
+			t.Errorf("step %d doesn't say why %v was chosen", i+1, step.Guess)
+		}
+		if want := getHint(step.Guess, "light"); step.Hint != want {
+			t.Errorf("step %d got %v, want %v", i+1, step.Hint, want)
+		}
+		if i > 0 && step.Remaining >= steps[i-1].Remaining {
+			t.Errorf("step %d left %d candidates, not fewer than %d", i+1, step.Remaining, steps[i-1].Remaining)
+		}
+	}
+
+	last := steps[len(steps)-1]
+	if last.Guess != "light" || last.Hint != allGreen() || last.Remaining != 1 {
+		t.Errorf("last step = %+v, want light solved", last)
+	}
+
+	if steps := SolveVerbose("zzzzz"); steps != nil {
+		t.Errorf("SolveVerbose for a non-answer = %+v, want nil", steps)
+	}
+}
+
+func TestSimulateAllMaxGuesses(t *testing.T) {
+	useFixture(t)
+	setForTest(t, &MaxGuesses, 3)
+
+	// wight is the only fixture answer that takes 4 guesses
+	numGuesses, failures := SimulateAll()
+	if !slices.Equal(failures, []string{"wight"}) {
+		t.Errorf("failures = %v, want [wight]", failures)
+	}
+	if numGuesses[2]+numGuesses[3] != len(answers)-1 || numGuesses[4] != 0 {
+		t.Errorf("numGuesses = %v, want every other answer in 3 or fewer", numGuesses)
+	}
+}
+
+func TestSimulateAllRespectsMaxWorkers(t *testing.T) {
+	useFixture(t)
+	setForTest(t, &MaxWorkers, 2)
+	peak := countWorkers(t)
+
+	SimulateAll()
+	if got := peak(); got > 2 {
+		t.Errorf("%d games ran at once with MaxWorkers = 2", got)
+	}
+}
+
+func TestHardestAnswers(t *testing.T) {
+	useFixture(t)
+
+	hardest := HardestAnswers("fmnst", 5)
+	if len(hardest) != 5 {
+		t.Fatalf("got %d answers, want 5", len(hardest))
+	}
+	if hardest[0].Word != "wight" || hardest[0].Guesses != 4 {
+		t.Errorf("hardest = %+v, want wight in 4", hardest[0])
+	}
+	for i := 1; i < len(hardest); i++ {
+		if hardest[i].Guesses > hardest[i-1].Guesses {
+			t.Errorf("not sorted: %+v after %+v", hardest[i], hardest[i-1])
+		}
+	}
+
+	if all := HardestAnswers("fmnst", 100); len(all) != len(answers) {
+		t.Errorf("asking for more than there are gave %d, want %d", len(all), len(answers))
+	}
+
+	if got := HardestAnswers("zzzzz", 5); got != nil {
+		t.Errorf("HardestAnswers(zzzzz) = %v, want nil", got)
+	}
+	if got := HardestAnswers("fmnst", -1); got != nil {
+		t.Errorf("HardestAnswers(fmnst, -1) = %v, want nil", got)
+	}
+	if got := HardestAnswers("fmnst", 0); len(got) != 0 {
+		t.Errorf("HardestAnswers(fmnst, 0) = %v, want none", got)
+	}
+}
+
+func TestGuessesFromAnswersOnly(t *testing.T) {
+	useFixture(t)
+	setForTest(t, &GuessesFromAnswersOnly, true)
+
+	isAnswer := func(word string) bool { return slices.Contains(answers, word) }
+
+	// fmnst is the best opener when it's allowed
+	if guess, _ := chooseGuess(allAnswers()); !isAnswer(guess) {
+		t.Errorf("opener %v isn't an answer", guess)
+	}
+	for _, answer := range answers {
+		for _, step := range SolveVerbose(answer) {
+			if !isAnswer(step.Guess) {
+				t.Errorf("solving %v played %v", answer, step.Guess)
+			}
+		}
+	}
+	for _, scored := range RankAllGuesses(allAnswers()) {
+		if !isAnswer(scored.Guess) {
+			t.Errorf("RankAllGuesses has %v", scored.Guess)
+		}
+	}
+	filtered, _ := uniqueLetterGuesses()
+	for _, guess := range filtered {
+		if !isAnswer(guess) {
+			t.Errorf("the pair search would try %v", guess)
+		}
+	}
+}
+
+func TestSecondGuessTable(t *testing.T) {
+	useFixture(t)
+
+	table := SecondGuessTable("fmnst")
+	counts := bucketCounts("fmnst", allAnswers())
+	if len(table) != len(counts) {
+		t.Errorf("table has %d hints, want one for each of the %d nonempty buckets", len(table), len(counts))
+	}
+
+	guessInfo := lookupGuessInfo("fmnst")
+	for hint, count := range counts {
+		second, ok := table[hint]
+		if !ok || second == "" {
+			t.Errorf("no second guess after %v", hint)
+			continue
+		}
+		if count == 1 {
+			bucket := guessInfo.hintBitvec(hint)
+			if only := answers[bucket.FirstSet()]; second != only {
+				t.Errorf("after %v only %v is left, but the table says %v", hint, only, second)
+			}
+		}
+	}
+
+	if got := SecondGuessTable("zzzzz"); got != nil {
+		t.Errorf("SecondGuessTable(zzzzz) = %v, want nil", got)
+	}
+}
+
+func TestUnresolvableWithin(t *testing.T) {
+	// every guess only rules out itself, so the solver needs one guess per word
+	useWordLists(t, "testdata/ills.txt", "testdata/ills.txt")
+
+	if got := UnresolvableWithin("bills", 6); !slices.Equal(got, []string{"pills"}) {
+		t.Errorf("UnresolvableWithin(bills, 6) = %v, want [pills]", got)
+	}
+	if got := UnresolvableWithin("bills", 7); len(got) != 0 {
+		t.Errorf("UnresolvableWithin(bills, 7) = %v, want none", got)
+	}
+	if got := UnresolvableWithin("zzzzz", 6); got != nil {
+		t.Errorf("UnresolvableWithin(zzzzz) = %v, want nil", got)
+	}
+}
+
+func TestBestOpenerByExpectedGuesses(t *testing.T) {
+	useFixture(t)
+
+	best, bestVal := BestOpenerByExpectedGuesses()
+	candidates := allAnswers()
+	if got := expectedGuessesDepth2(best, candidates); got != bestVal {
+		t.Errorf("returned %v for %v, but it scores %v", bestVal, best, got)
+	}
+
+	for _, opener := range guesses {
+		if val := expectedGuessesDepth2(opener, candidates); val < bestVal {
+			t.Errorf("%v scores %v, better than %v's %v", opener, val, best, bestVal)
+		}
+	}
+}
+
+func TestExportSolvePaths(t *testing.T) {
+	useFixture(t)
+	// wight takes 4 guesses, the rest 3 or fewer
+	setForTest(t, &MaxGuesses, 3)
+
+	var out strings.Builder
+	if err := ExportSolvePaths("fmnst", &out); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != len(answers)+1 || !slices.Equal(rows[0], []string{"answer", "guesses", "path"}) {
+		t.Fatalf("got %d rows starting with %q, want a header and %d rows", len(rows), rows[0], len(answers))
+	}
+	for i, row := range rows[1:] {
+		if row[0] != answers[i] {
+			t.Errorf("row %d is for %v, want %v", i+1, row[0], answers[i])
+		}
+		path := strings.Fields(row[2])
+		if path[0] != "fmnst" || len(path) > 3 {
+			t.Errorf("%v: path %q", row[0], row[2])
+		}
+
+		want := strconv.Itoa(len(path))
+		if row[0] == "wight" {
+			want = "FAIL"
+		}
+		if row[1] != want {
+			t.Errorf("%v: count %v for path %q, want %v", row[0], row[1], row[2], want)
+		}
+	}
+
+	if got := rows[slices.Index(answers, "light")+1]; !slices.Equal(got, []string{"light", "3", "fmnst taker light"}) {
+		t.Errorf("light's row = %q", got)
+	}
+
+	out.Reset()
+	if err := ExportSolvePaths("zzzzz", &out); err == nil || out.Len() != 0 {
+		t.Errorf("ExportSolvePaths(zzzzz) = %v and wrote %q, want an error and nothing", err, out.String())
+	}
+}
+
+func TestBestOpenerForSubset(t *testing.T) {
+	useFixture(t)
+
+	// the -ight and -ate words
+	subset := fixtureBitvec(t, "fight", "light", "might", "night", "right", "sight", "tight", "wight", "crate", "grate")
+	best := BestOpenerForSubset(subset)
+
+	bestVal := ExpectedRemaining(best, subset)
+	for _, guess := range guesses {
+		if val := ExpectedRemaining(guess, subset); val < bestVal {
+			t.Errorf("%v leaves %v on average, fewer than %v's %v", guess, val, best, bestVal)
+		}
+	}
+
+	if got := BestOpenerForSubset(NewBitvec(len(answers))); got != "" {
+		t.Errorf("BestOpenerForSubset(nothing) = %v, want none", got)
+	}
+}
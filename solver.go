@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Solver is a guess list, an answer list, and the hints between them, so
+// several word lists can be used side by side. The package level functions
+// use the one made from guesses, answers, and guessesMap; options like
+// CurrentHintMode and RequiredLetters apply to every Solver.
+type Solver struct {
+	guesses    []string
+	answers    []string
+	guessesMap map[string]*GuessInfo
+}
+
+// NewSolver returns a Solver for the given lists. Call Build before using it.
+func NewSolver(guesses, answers []string) *Solver {
+	return &Solver{
+		guesses:    guesses,
+		answers:    answers,
+		guessesMap: map[string]*GuessInfo{},
+	}
+}
+
+// defaultSolver returns the Solver the package level functions use. It's a
+// snapshot, so it keeps using the same guessesMap if UpdateCache swaps in a
+// new one.
+func defaultSolver() Solver {
+	guessesMapMu.RLock()
+	defer guessesMapMu.RUnlock()
+	return Solver{guesses, answers, guessesMap}
+}
+
+// Build calculates the hints and bitvecs for every guess against every
+// answer. Bitvecs are always built up front, whatever LazyBitvecs says, since
+// building them lazily only works for the package level lists. If ctx is
+// cancelled it stops early and returns ctx.Err(), leaving s unchanged.
+func (s *Solver) Build(ctx context.Context) error {
+	m := make(map[string]*GuessInfo, len(s.guesses))
+	if err := calculateHintsFor(ctx, m, s.guesses, s.answers); err != nil {
+		return err
+	}
+	calculateBitvecsFor(m, s.guesses, s.answers)
+
+	s.guessesMap = m
+	return nil
+}
+
+func (s *Solver) lookupBitvec(guess, answer string) *Bitvec {
+	guessInfo := s.guessesMap[guess]
+	return guessInfo.hintBitvec(guessInfo.AnswerHints[answer])
+}
+
+// allAnswers returns a bitvec with every answer set
+func (s *Solver) allAnswers() *Bitvec {
+	candidates := NewBitvec(len(s.answers))
+	candidates.SetAll()
+
+	return candidates
+}
+
+// allowedGuesses returns the words the solver is allowed to guess
+func (s *Solver) allowedGuesses() []string {
+	allowed := s.guesses
+	if GuessesFromAnswersOnly {
+		allowed = s.answers
+	}
+
+	if len(RequiredLetters) == 0 {
+		return allowed
+	}
+
+	var filtered []string
+	for _, guess := range allowed {
+		if hasLetters(guess, RequiredLetters) {
+			filtered = append(filtered, guess)
+		}
+	}
+
+	return filtered
+}
+
+// bucketCounts returns the number of candidates in each of guess's hint
+// buckets. A guess that isn't in the guess list has no buckets.
+func (s *Solver) bucketCounts(guess string, candidates *Bitvec) map[Hint]int {
+	counts := make(map[Hint]int)
+
+	guessInfo := s.guessesMap[guess]
+	if guessInfo == nil {
+		return counts
+	}
+
+	scratch := getScratchBitvec()
+	defer putScratchBitvec(scratch)
+
+	for hint := range guessInfo.HintsMap {
+		guessInfo.hintBitvec(hint).AndInto(scratch, candidates)
+		count := scratch.Count
+		if count > 0 {
+			counts[hint] = count
+		}
+	}
+
+	return counts
+}
+
+// ExpectedRemaining is the average number of candidates left after playing
+// guess, assuming each candidate is equally likely to be the answer
+func (s *Solver) ExpectedRemaining(guess string, candidates *Bitvec) float64 {
+	if candidates.Count == 0 {
+		return 0
+	}
+
+	var tot float64
+	for _, count := range s.bucketCounts(guess, candidates) {
+		tot += float64(count * count)
+	}
+
+	return tot / float64(candidates.Count)
+}
+
+// WorstCaseBucket is the size of guess's largest hint bucket within candidates
+func (s *Solver) WorstCaseBucket(guess string, candidates *Bitvec) int {
+	worst := 0
+	for _, count := range s.bucketCounts(guess, candidates) {
+		worst = max(worst, count)
+	}
+
+	return worst
+}
+
+// SolveConservative picks the guess with the smallest worst-case bucket out of
+// the guesses whose expected remaining candidates is within epsilon of the
+// best. Ties go to the lower expected remaining, then to list order.
+func (s *Solver) SolveConservative(candidates *Bitvec, epsilon float64) string {
+	if candidates.Count == 0 {
+		return ""
+	}
+
+	allowed := s.allowedGuesses()
+	if len(allowed) == 0 {
+		return ""
+	}
+	expected := make([]float64, len(allowed))
+	worst := make([]int, len(allowed))
+
+	wg := sync.WaitGroup{}
+
+	for i, guess := range allowed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// sum as ints so equal guesses come out exactly equal no matter
+			// what order the buckets are visited in
+			sumSquares := 0
+			for _, count := range s.bucketCounts(guess, candidates) {
+				sumSquares += count * count
+				worst[i] = max(worst[i], count)
+			}
+			expected[i] = float64(sumSquares) / float64(candidates.Count)
+		}()
+	}
+
+	wg.Wait()
+
+	bestExpected := slices.Min(expected)
+
+	bestIdx := -1
+	for i := range allowed {
+		if expected[i] > bestExpected+epsilon {
+			continue
+		}
+		if bestIdx == -1 ||
+			worst[i] < worst[bestIdx] ||
+			(worst[i] == worst[bestIdx] && expected[i] < expected[bestIdx]) {
+			bestIdx = i
+		}
+	}
+
+	return allowed[bestIdx]
+}
+
+// chooseGuess picks the next guess for candidates and explains why. The guess
+// is "" if there are no candidates or no allowed guess, e.g. when no guess has
+// every one of RequiredLetters.
+func (s *Solver) chooseGuess(candidates *Bitvec) (string, string) {
+	if candidates.Count == 0 {
+		return "", "no candidates left"
+	}
+
+	// with 2 or fewer candidates left, guessing one of them is never worse,
+	// as long as it has RequiredLetters
+	if candidates.Count <= 2 {
+		for i := candidates.FirstSet(); i != -1; i = candidates.NextSet(i) {
+			if hasLetters(s.answers[i], RequiredLetters) {
+				return s.answers[i],
+					fmt.Sprintf("%d candidate(s) left, guessing one", candidates.Count)
+			}
+		}
+	}
+
+	guess := s.SolveConservative(candidates, 0)
+	if guess == "" {
+		return "", "no allowed guess to play"
+	}
+	return guess, fmt.Sprintf("lowest expected remaining (%.2f, worst case %d) out of %d candidates",
+		s.ExpectedRemaining(guess, candidates), s.WorstCaseBucket(guess, candidates), candidates.Count)
+}
+
+// NextGuess picks the guess SolveVerbose would play with candidates left.
+// Returns "" if there are no candidates or no allowed guess.
+func (s *Solver) NextGuess(candidates *Bitvec) string {
+	guess, _ := s.chooseGuess(candidates)
+	return guess
+}
+
+// AvgNumCandidates is the average over every answer of how many candidates
+// are left after playing firstGuess and then the guesses, counting 1 once
+// there are 2 or fewer
+func (s *Solver) AvgNumCandidates(firstGuess string, guesses ...string) float64 {
+	return float64(s.sumNumCandidates(s.answers, firstGuess, guesses)) / float64(len(s.answers))
+}
+
+// sumNumCandidates adds up how many candidates are left for each of
+// someAnswers after playing the guesses, counting 1 once there are 2 or fewer
+func (s *Solver) sumNumCandidates(someAnswers []string, firstGuess string, guesses []string) int64 {
+	var tot int64
+
+	scratch := getScratchBitvec()
+	defer putScratchBitvec(scratch)
+
+	for _, answer := range someAnswers {
+		tot += int64(s.numCandidatesLeft(answer, firstGuess, guesses, scratch))
+	}
+
+	return tot
+}
+
+// numCandidatesLeft is how many candidates are left for answer after playing
+// the guesses, or 1 once there are 2 or fewer. scratch is used for the
+// intermediate results.
+func (s *Solver) numCandidatesLeft(answer, firstGuess string, guesses []string, scratch *Bitvec) int {
+	bitvec := s.lookupBitvec(firstGuess, answer)
+
+	for _, guess := range guesses {
+		if bitvec.Count <= 2 {
+			return 1
+		}
+		bitvec.AndInto(scratch, s.lookupBitvec(guess, answer))
+		bitvec = scratch
+	}
+
+	return bitvec.Count
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestSolveConservative(t *testing.T) {
+	useFixture(t)
+
+	const epsilon = 0.25
+	candidates := fixtureBitvec(t, "baker", "fight", "irate", "light", "might", "right",
+		"sight", "slate", "stale", "steal", "taker", "wight")
+
+	average := MinBy(guesses, func(guess string) float64 {
+		return ExpectedRemaining(guess, candidates)
+	})
+	conservative := SolveConservative(candidates, epsilon)
+
+	if ExpectedRemaining(conservative, candidates) > ExpectedRemaining(average, candidates)+epsilon {
+		t.Errorf("%v expects %.3f left, more than %v's %.3f plus epsilon", conservative,
+			ExpectedRemaining(conservative, candidates), average, ExpectedRemaining(average, candidates))
+	}
+	if WorstCaseBucket(conservative, candidates) >= WorstCaseBucket(average, candidates) {
+		t.Errorf("%v can leave %d, no better than %v's %d", conservative,
+			WorstCaseBucket(conservative, candidates), average, WorstCaseBucket(average, candidates))
+	}
+
+	if got := SolveConservative(candidates, 0); ExpectedRemaining(got, candidates) != ExpectedRemaining(average, candidates) {
+		t.Errorf("with no slack SolveConservative picked %v, which isn't tied for the best average", got)
+	}
+}
+
+func TestSolverIndependentOfGlobals(t *testing.T) {
+	useFixture(t)
+
+	fixture := NewSolver(guesses, answers)
+	if err := fixture.Build(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	candidates := fixtureBitvec(t, "crate", "grate", "irate", "taker")
+	for _, guess := range guesses {
+		if got, want := fixture.ExpectedRemaining(guess, candidates), ExpectedRemaining(guess, candidates); got != want {
+			t.Errorf("Solver.ExpectedRemaining(%v) = %v, package level gives %v", guess, got, want)
+		}
+		if got, want := fixture.AvgNumCandidates(guess), AvgNumCandidates(guess); got != want {
+			t.Errorf("Solver.AvgNumCandidates(%v) = %v, package level gives %v", guess, got, want)
+		}
+	}
+	if got, want := fixture.NextGuess(candidates), "tight"; got != want {
+		t.Errorf("Solver.NextGuess = %v, want %v", got, want)
+	}
+
+	ills, err := readWordList("testdata/ills.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtureMap := guessesMap
+	s := NewSolver(ills, ills)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Build(ctx); !errors.Is(err, context.Canceled) || len(s.guessesMap) != 0 {
+		t.Fatalf("Build with a cancelled context = %v and built %d guesses", err, len(s.guessesMap))
+	}
+
+	if err := s.Build(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// bills only tells itself apart from the other 6
+	if got, want := s.AvgNumCandidates("bills"), (1+6*6)/7.0; got != want {
+		t.Errorf("AvgNumCandidates(bills) on the ills list = %v, want %v", got, want)
+	}
+	if len(s.guessesMap) != len(ills) {
+		t.Errorf("built %d guesses, want %d", len(s.guessesMap), len(ills))
+	}
+
+	if !maps.Equal(guessesMap, fixtureMap) || lookupGuessInfo("bills") != nil || len(answers) != 20 {
+		t.Error("building a Solver changed the package level lists")
+	}
+}
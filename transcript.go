@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// guessResultJSON is how a GuessResult looks on the wire. Hint is the emoji
+// string when written, but can also be read as a digit string like "20100" or
+// an array like [2,0,1,0,0].
+type guessResultJSON struct {
+	Guess string          `json:"guess"`
+	Hint  json.RawMessage `json:"hint"`
+}
+
+func (r GuessResult) MarshalJSON() ([]byte, error) {
+	hint, err := json.Marshal(r.Hint.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(guessResultJSON{r.Guess, hint})
+}
+
+func (r *GuessResult) UnmarshalJSON(data []byte) error {
+	var raw guessResultJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var digits []int
+	var str string
+	if err := json.Unmarshal(raw.Hint, &str); err == nil {
+		digits, err = hintDigits(str)
+		if err != nil {
+			return err
+		}
+	} else if err := json.Unmarshal(raw.Hint, &digits); err != nil {
+		return fmt.Errorf("hint for %q must be a string or an array of digits", raw.Guess)
+	}
+
+	hint, err := hintFromDigits(digits)
+	if err != nil {
+		return err
+	}
+
+	r.Guess = raw.Guess
+	r.Hint = hint
+	return nil
+}
+
+// hintDigits turns an emoji hint like "⬜🟩🟨⬜⬜" or a digit string like
+// "02100" into one digit per letter
+func hintDigits(s string) ([]int, error) {
+	var digits []int
+	for _, ch := range s {
+		switch ch {
+		case '0', '⬜', '⬛':
+			digits = append(digits, 0)
+		case '1', '🟨':
+			digits = append(digits, 1)
+		case '2', '🟩':
+			digits = append(digits, 2)
+		case '3', '🟪':
+			digits = append(digits, 3)
+		default:
+			return nil, fmt.Errorf("invalid hint %q: unexpected %q", s, ch)
+		}
+	}
+
+	return digits, nil
+}
+
+// hintFromDigits packs WordLen base HintBase digits into a Hint, first letter
+// first
+func hintFromDigits(digits []int) (Hint, error) {
+	if len(digits) != WordLen {
+		return 0, fmt.Errorf("hint has %d letters, want %d", len(digits), WordLen)
+	}
+
+	for _, d := range digits {
+		if d < 0 || d >= HintBase() {
+			return 0, fmt.Errorf("hint digit %d out of range", d)
+		}
+	}
+
+	return HintFromSequence([WordLen]int(digits)), nil
+}
+
+// LoadTranscript reads a JSON array of guess results, e.g.
+// [{"guess":"crane","hint":"⬜🟩🟨⬜⬜"}, ...]
+func LoadTranscript(r io.Reader) ([]GuessResult, error) {
+	var transcript []GuessResult
+	if err := json.NewDecoder(r).Decode(&transcript); err != nil {
+		return nil, fmt.Errorf("reading transcript: %w", err)
+	}
+
+	for i, result := range transcript {
+		if err := checkWord(result.Guess); err != nil {
+			return nil, fmt.Errorf("reading transcript: guess %d: %w", i+1, err)
+		}
+		transcript[i].Guess = strings.ToLower(result.Guess)
+	}
+
+	return transcript, nil
+}
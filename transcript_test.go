@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGuessResultJSONRoundTrip(t *testing.T) {
+	transcript := []GuessResult{
+		{"fmnst", getHint("fmnst", "light")},
+		{"taker", getHint("taker", "light")},
+		{"light", allGreen()},
+	}
+
+	data, err := json.Marshal(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"hint":"🟩🟩🟩🟩🟩"`) {
+		t.Errorf("the all green row isn't written as emoji: %s", data)
+	}
+
+	loaded, err := LoadTranscript(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded, transcript) {
+		t.Errorf("round trip gave %v, want %v", loaded, transcript)
+	}
+}
+
+func TestLoadTranscriptHintForms(t *testing.T) {
+	input := `[
+		{"guess":"CRANE","hint":"⬜🟩🟨⬜⬛"},
+		{"guess":"slate","hint":"02100"},
+		{"guess":"tonal","hint":[2,2,2,2,2]}
+	]`
+
+	loaded, err := LoadTranscript(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hint, err := hintFromDigits([]int{0, 2, 1, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GuessResult{
+		{"crane", hint},
+		{"slate", hint},
+		{"tonal", allGreen()},
+	}
+	if !slices.Equal(loaded, want) {
+		t.Errorf("LoadTranscript() = %v, want %v", loaded, want)
+	}
+
+	for _, bad := range []string{
+		`[{"guess":"crane","hint":"0210"}]`,
+		`[{"guess":"crane","hint":"02x00"}]`,
+		`[{"guess":"cat","hint":"02100"}]`,
+	} {
+		if _, err := LoadTranscript(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadTranscript(%s) didn't fail", bad)
+		}
+	}
+}
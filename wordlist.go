@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ValidateWordList reports every entry in words that would corrupt the hints:
+// anything that isn't exactly WordLen letters from Alphabet, and repeats. When
+// CaseSensitive is off, upper case letters are allowed and words that only
+// differ in case count as repeats. Line numbers are 1-based to match the word
+// list files.
+func ValidateWordList(words []string) []string {
+	var problems []string
+	firstSeen := make(map[string]int)
+
+	for i, word := range words {
+		line := i + 1
+
+		if len(word) != WordLen {
+			problems = append(problems, fmt.Sprintf("line %d: '%s' has %d letters", line, word, len(word)))
+		}
+
+		// bytes rather than runes, since getHint compares bytes
+		for i := range len(word) {
+			if letterIndex(word[i]) == -1 {
+				problems = append(problems, fmt.Sprintf("line %d: '%s' has non-letter %q", line, word, word[i]))
+				break
+			}
+		}
+
+		key := word
+		if !CaseSensitive {
+			key = strings.ToLower(word)
+		}
+		if prev, ok := firstSeen[key]; ok {
+			problems = append(problems, fmt.Sprintf("line %d: '%s' duplicates line %d", line, word, prev))
+		} else {
+			firstSeen[key] = line
+		}
+	}
+
+	return problems
+}
+
+// DuplicateWords returns the words that appear more than once in the guess
+// list and in the answer list, each listed once in the order its first repeat
+// shows up
+func DuplicateWords() (inGuesses, inAnswers []string) {
+	return duplicates(guesses), duplicates(answers)
+}
+
+func duplicates(words []string) []string {
+	var repeated []string
+	seen := make(map[string]int)
+	for _, word := range words {
+		seen[word]++
+		if seen[word] == 2 {
+			repeated = append(repeated, word)
+		}
+	}
+
+	return repeated
+}
+
+// checkWord returns an error if word isn't WordLen letters, since getHint
+// would index past the end of it
+func checkWord(word string) error {
+	if len(word) != WordLen {
+		return fmt.Errorf("%q has %d letters, want %d", word, len(word), WordLen)
+	}
+
+	return nil
+}
+
+// SetWordLists swaps in new guess and answer lists and throws away everything
+// derived from the old ones, so calculateHints and calculateBitvecs have to be
+// run again. Mostly useful for running against a small fixed list.
+func SetWordLists(newGuesses, newAnswers []string) {
+	guesses = newGuesses
+	answers = newAnswers
+	setGuessesMap(map[string]*GuessInfo{})
+
+	legalGuessesMu.Lock()
+	legalGuesses = nil
+	legalGuessesMu.Unlock()
+}
+
+// legalGuesses is guesses as a set for IsLegalGuess, built the first time
+// it's needed and thrown away by SetWordLists
+var (
+	legalGuesses   map[string]bool
+	legalGuessesMu sync.Mutex
+)
+
+// IsLegalGuess reports whether word is in the guess list, i.e. whether a real
+// game would accept it
+func IsLegalGuess(word string) bool {
+	legalGuessesMu.Lock()
+	defer legalGuessesMu.Unlock()
+
+	if legalGuesses == nil {
+		legalGuesses = make(map[string]bool, len(guesses))
+		for _, guess := range guesses {
+			legalGuesses[guess] = true
+		}
+	}
+
+	return legalGuesses[word]
+}
+
+// LoadWordLists reads newline separated guess and answer lists, like the ones
+// in io/ or testdata/, and passes them to SetWordLists
+func LoadWordLists(guessesPath, answersPath string) error {
+	newGuesses, err := readWordList(guessesPath)
+	if err != nil {
+		return err
+	}
+	newAnswers, err := readWordList(answersPath)
+	if err != nil {
+		return err
+	}
+
+	SetWordLists(newGuesses, newAnswers)
+	return nil
+}
+
+func readWordList(path string) ([]string, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading word list: %w", err)
+	}
+
+	return strings.Fields(string(file)), nil
+}
+
+// nytWordArray matches a JS array literal of nothing but 5 letter strings,
+// which is how the word lists appear in NYT's Wordle bundle
+var nytWordArray = regexp.MustCompile(`\[(?:\s*"[a-z]{5}"\s*,)*\s*"[a-z]{5}"\s*\]`)
+
+// ParseNYTBundle pulls the word lists out of the JS bundle NYT serves Wordle
+// from. The bundle has two arrays of 5 letter words: the answers, and the
+// extra words that are only allowed as guesses, which is always the bigger
+// one. Any smaller arrays are ignored. The returned guesses include the
+// answers and are sorted, like io/guesses.txt; the answers are sorted like
+// io/answers.txt.
+func ParseNYTBundle(r io.Reader) (guesses, answers []string, err error) {
+	bundle, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading bundle: %w", err)
+	}
+
+	var arrays [][]string
+	for _, match := range nytWordArray.FindAll(bundle, -1) {
+		var words []string
+		if err := json.Unmarshal(match, &words); err != nil {
+			return nil, nil, fmt.Errorf("parsing word array: %w", err)
+		}
+		arrays = append(arrays, words)
+	}
+
+	if len(arrays) < 2 {
+		return nil, nil, errors.New("bundle doesn't have two word arrays")
+	}
+
+	// the two biggest arrays are the word lists, biggest first
+	slices.SortStableFunc(arrays, func(a, b []string) int {
+		return len(b) - len(a)
+	})
+	extraGuesses, answers := arrays[0], slices.Clone(arrays[1])
+
+	guesses = slices.Concat(extraGuesses, answers)
+	slices.Sort(guesses)
+	guesses = slices.Compact(guesses)
+	slices.Sort(answers)
+
+	return guesses, answers, nil
+}
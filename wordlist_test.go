@@ -0,0 +1,214 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateWordList(t *testing.T) {
+	problems := ValidateWordList([]string{"crane", "thre", "sl4te", "tonal", "crane"})
+
+	want := []string{
+		"line 2: 'thre' has 4 letters",
+		"line 3: 'sl4te' has non-letter",
+		"line 5: 'crane' duplicates line 1",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("got %d problems, want %d: %q", len(problems), len(want), problems)
+	}
+	for i := range want {
+		if !strings.HasPrefix(problems[i], want[i]) {
+			t.Errorf("problem %d = %q, want it to start with %q", i, problems[i], want[i])
+		}
+	}
+
+	if problems := ValidateWordList([]string{"crane", "slate"}); len(problems) != 0 {
+		t.Errorf("a clean list has problems: %q", problems)
+	}
+}
+
+func TestBuildGuessesMapRejectsBadWords(t *testing.T) {
+	setForTest(t, &ProgressFunc, func(done, total int) {})
+	SetWordLists([]string{"crane", "thre"}, []string{"crane"})
+
+	err := buildGuessesMap()
+	if err == nil || !strings.Contains(err.Error(), "'thre' has 4 letters") {
+		t.Errorf("buildGuessesMap() = %v, want it to report thre", err)
+	}
+}
+
+func TestSetWordLists(t *testing.T) {
+	useFixture(t)
+
+	if len(guesses) != 23 || len(answers) != 20 {
+		t.Fatalf("fixture has %d guesses and %d answers, want 23 and 20", len(guesses), len(answers))
+	}
+
+	guessesMapMu.RLock()
+	defer guessesMapMu.RUnlock()
+
+	if len(guessesMap) != len(guesses) {
+		t.Errorf("guessesMap has %d guesses, want %d", len(guessesMap), len(guesses))
+	}
+	for guess, guessInfo := range guessesMap {
+		if len(guessInfo.AnswerHints) != len(answers) {
+			t.Errorf("%v has hints for %d answers, want %d", guess, len(guessInfo.AnswerHints), len(answers))
+		}
+		for hint, hintInfo := range guessInfo.HintsMap {
+			if hintInfo.Bitvec.Size != len(answers) {
+				t.Errorf("%v's bitvec for %v has size %d, want %d", guess, hint, hintInfo.Bitvec.Size, len(answers))
+			}
+		}
+	}
+}
+
+func TestAccentedWordsRejected(t *testing.T) {
+	// café is 5 bytes, so only the letter check catches it
+	list, err := readWordList("testdata/oddguesses.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := strings.Join(ValidateWordList(list), "\n")
+	if !strings.Contains(problems, "line 4: 'café' has non-letter") {
+		t.Errorf("ValidateWordList didn't report café:\n%s", problems)
+	}
+
+	setForTest(t, &ProgressFunc, func(done, total int) {})
+	SetWordLists([]string{"crane", "café"}, []string{"crane"})
+	if err := buildGuessesMap(); err == nil || !strings.Contains(err.Error(), "café") {
+		t.Errorf("buildGuessesMap() = %v, want it to report café", err)
+	}
+
+	// crêpe looks like 5 letters but is 6 bytes
+	if _, err := GetHint("crêpe", "crane"); err == nil {
+		t.Error("GetHint(crêpe, crane) didn't return an error")
+	}
+}
+
+func TestBuildGuessesMapEmptyLists(t *testing.T) {
+	setForTest(t, &ProgressFunc, func(done, total int) {})
+	t.Chdir(t.TempDir())
+
+	for _, lists := range [][2][]string{
+		{nil, nil},
+		{{"crane"}, nil},
+		{nil, {"crane"}},
+	} {
+		SetWordLists(lists[0], lists[1])
+		err := buildGuessesMap()
+		if err == nil || !strings.Contains(err.Error(), "can't build the cache") {
+			t.Errorf("%d guesses and %d answers: buildGuessesMap() = %v", len(lists[0]), len(lists[1]), err)
+		}
+	}
+}
+
+func TestParseNYTBundle(t *testing.T) {
+	bundle := `!function(){var Ma=["tonal","crane","light"],Oa=["fmnst","zzzzz","roate","soare"],` +
+		`x=["ab","cd"],y=["short"];function f(e){return Ma[e%Ma.length]}` +
+		`var settings={"theme":"dark","words":["nope"]};}();`
+
+	guesses, answers, err := ParseNYTBundle(strings.NewReader(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantGuesses := []string{"crane", "fmnst", "light", "roate", "soare", "tonal", "zzzzz"}
+	if !slices.Equal(guesses, wantGuesses) {
+		t.Errorf("guesses = %v, want %v", guesses, wantGuesses)
+	}
+	if wantAnswers := []string{"crane", "light", "tonal"}; !slices.Equal(answers, wantAnswers) {
+		t.Errorf("answers = %v, want %v", answers, wantAnswers)
+	}
+
+	if _, _, err := ParseNYTBundle(strings.NewReader(`var Ma=["crane","light"];`)); err == nil {
+		t.Error("a bundle with one word array didn't return an error")
+	}
+}
+
+func TestIsLegalGuess(t *testing.T) {
+	useFixture(t)
+
+	for word, want := range map[string]bool{"fmnst": true, "crane": true, "zzzzz": false, "cran": false, "": false} {
+		if got := IsLegalGuess(word); got != want {
+			t.Errorf("IsLegalGuess(%q) = %v, want %v", word, got, want)
+		}
+	}
+
+	// the set is rebuilt for new lists
+	SetWordLists([]string{"zzzzz"}, []string{"zzzzz"})
+	if !IsLegalGuess("zzzzz") || IsLegalGuess("fmnst") {
+		t.Error("IsLegalGuess still uses the old guess list")
+	}
+}
+
+// benchmarkLegalGuess looks up a word near the end of the real guess list,
+// where a linear scan is slowest
+func benchmarkLegalGuess(b *testing.B, isLegal func(string) bool) {
+	list, err := readWordList("io/guesses.txt")
+	if err != nil {
+		b.Skip("needs the real word lists in io/:", err)
+	}
+	SetWordLists(list, list[:1])
+	word := list[len(list)-2]
+
+	for b.Loop() {
+		if !isLegal(word) {
+			b.Fatalf("%v isn't legal", word)
+		}
+	}
+}
+
+func BenchmarkIsLegalGuess(b *testing.B) {
+	benchmarkLegalGuess(b, IsLegalGuess)
+}
+
+func BenchmarkIsLegalGuessLinear(b *testing.B) {
+	benchmarkLegalGuess(b, func(word string) bool { return slices.Contains(guesses, word) })
+}
+
+func TestDuplicateWords(t *testing.T) {
+	useFixture(t)
+	if inGuesses, inAnswers := DuplicateWords(); len(inGuesses) != 0 || len(inAnswers) != 0 {
+		t.Errorf("fixture has duplicates %v and %v", inGuesses, inAnswers)
+	}
+
+	if err := LoadWordLists("testdata/dupguesses.txt", "testdata/dupanswers.txt"); err != nil {
+		t.Fatal(err)
+	}
+	inGuesses, inAnswers := DuplicateWords()
+	if want := []string{"crane", "slate"}; !slices.Equal(inGuesses, want) {
+		t.Errorf("duplicate guesses = %v, want %v", inGuesses, want)
+	}
+	if want := []string{"light"}; !slices.Equal(inAnswers, want) {
+		t.Errorf("duplicate answers = %v, want %v", inAnswers, want)
+	}
+}
+
+func TestCaseSensitive(t *testing.T) {
+	setForTest(t, &CaseSensitive, true)
+	if got := getHint("Crane", "crane"); got.Digits() != "02222" {
+		t.Errorf("strict getHint(Crane, crane) = %s, want 02222", got.Digits())
+	}
+	if problems := ValidateWordList([]string{"Crane"}); len(problems) != 1 || !strings.Contains(problems[0], "non-letter 'C'") {
+		t.Errorf("strict ValidateWordList(Crane) = %q", problems)
+	}
+
+	CaseSensitive = false
+	for _, pair := range [][2]string{{"Crane", "crane"}, {"CRANE", "crane"}, {"crane", "CrAnE"}} {
+		if got := getHint(pair[0], pair[1]); got != allGreen() {
+			t.Errorf("case insensitive getHint(%s, %s) = %s, want all green", pair[0], pair[1], got.Digits())
+		}
+	}
+	if got := getHint("Slate", "steal"); got != getHint("slate", "steal") {
+		t.Errorf("case insensitive getHint(Slate, steal) = %s, want %s", got.Digits(), getHint("slate", "steal").Digits())
+	}
+	if letterIndex('C') != letterIndex('c') {
+		t.Errorf("letterIndex(C) = %d, want %d", letterIndex('C'), letterIndex('c'))
+	}
+
+	problems := ValidateWordList([]string{"Crane", "slate", "crane"})
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], "line 3: 'crane' duplicates line 1") {
+		t.Errorf("case insensitive ValidateWordList = %q", problems)
+	}
+}
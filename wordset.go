@@ -0,0 +1,60 @@
+package main
+
+// WordSet is a Bitvec together with the word list its indices refer to, so
+// callers can work with words instead of raw indices
+type WordSet struct {
+	bv    *Bitvec
+	words []string
+	index map[string]int
+}
+
+// NewWordSet returns an empty set over words. Sets over the answer list can
+// be made with NewWordSet(answers).
+func NewWordSet(words []string) WordSet {
+	index := make(map[string]int, len(words))
+	for i, word := range words {
+		index[word] = i
+	}
+
+	return WordSet{NewBitvec(len(words)), words, index}
+}
+
+// Add puts word in the set. It reports false if word isn't in the word list.
+func (ws WordSet) Add(word string) bool {
+	i, ok := ws.index[word]
+	if ok {
+		ws.bv.Set(i)
+	}
+
+	return ok
+}
+
+// Contains reports whether word is in the set
+func (ws WordSet) Contains(word string) bool {
+	i, ok := ws.index[word]
+	return ok && ws.bv.Get(i)
+}
+
+// And returns the words in both sets. Both have to be over the same word list.
+func (ws WordSet) And(other WordSet) WordSet {
+	if len(ws.words) != len(other.words) {
+		panic("WordSet.And: sets are over different word lists")
+	}
+
+	return WordSet{ws.bv.And(other.bv), ws.words, ws.index}
+}
+
+// Len returns the number of words in the set
+func (ws WordSet) Len() int {
+	return ws.bv.Count
+}
+
+// Words returns the words in the set in word list order
+func (ws WordSet) Words() []string {
+	var words []string
+	for i := ws.bv.FirstSet(); i != -1; i = ws.bv.NextSet(i) {
+		words = append(words, ws.words[i])
+	}
+
+	return words
+}
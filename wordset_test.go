@@ -0,0 +1,65 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWordSet(t *testing.T) {
+	useFixture(t)
+
+	set := NewWordSet(answers)
+	for _, word := range []string{"tonal", "crane", "tonal"} {
+		if !set.Add(word) {
+			t.Errorf("Add(%s) = false", word)
+		}
+	}
+	if set.Add("fmnst") {
+		t.Error("Add(fmnst) = true for a word that isn't an answer")
+	}
+
+	if set.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", set.Len())
+	}
+	for word, want := range map[string]bool{"tonal": true, "crane": true, "zonal": false, "fmnst": false} {
+		if got := set.Contains(word); got != want {
+			t.Errorf("Contains(%s) = %v, want %v", word, got, want)
+		}
+	}
+	// list order, not the order they were added in
+	if got, want := set.Words(), []string{"crane", "tonal"}; !slices.Equal(got, want) {
+		t.Errorf("Words() = %v, want %v", got, want)
+	}
+}
+
+func TestWordSetAnd(t *testing.T) {
+	useFixture(t)
+
+	a, b := NewWordSet(answers), NewWordSet(answers)
+	for _, word := range []string{"light", "might", "night", "tonal"} {
+		a.Add(word)
+	}
+	for _, word := range []string{"zonal", "night", "baker", "light"} {
+		b.Add(word)
+	}
+
+	both := a.And(b)
+	if got, want := both.Words(), []string{"light", "night"}; !slices.Equal(got, want) {
+		t.Errorf("And() = %v, want %v", got, want)
+	}
+	if both.Contains("might") || a.Len() != 4 || b.Len() != 4 {
+		t.Error("And changed its operands")
+	}
+
+	empty := a.And(NewWordSet(answers))
+	if empty.Len() != 0 || empty.Words() != nil {
+		t.Errorf("And with an empty set = %v", empty.Words())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("And over different word lists didn't panic")
+		}
+	}()
+	a.And(NewWordSet(guesses))
+}